@@ -3,7 +3,10 @@ package main
 
 import (
 	// sys import
+	"fmt"
+	"math/rand"
 	"os"
+	"time"
 
 	// third part import
 	log "github.com/sirupsen/logrus"
@@ -25,6 +28,16 @@ func init() {
 
 func main() {
 
+	// the same seeded generator makes all the experiments reproducible
+	var seed int64 = time.Now().UTC().UnixNano()
+	var rng *rand.Rand = rand.New(mu.NewSource(seed))
+
+	log.WithFields(log.Fields{
+		"level": "info",
+		"place": "main",
+		"seed":  seed,
+	}).Info("Random generator initialized.")
+
 	// #############################################################################################################
 	// ######################################  Single layer perceptron model  ######################################
 	// #############################################################################################################
@@ -57,11 +70,11 @@ func main() {
 		var neuron mn.NeuronUnit = mn.NeuronUnit{Weights: make([]float64, len(patterns[0].Features)), Bias: bias, Lrate: learningRate}
 
 		// compute scores for each folds execution
-		var scores []float64 = v.KFoldValidation(&neuron, patterns, epochs, folds, shuffle)
+		var scores []float64 = v.KFoldValidation(mn.PerceptronModel(&neuron, epochs), patterns, folds, shuffle, rng)
 
 		// use simpler validation
 		var neuron2 mn.NeuronUnit = mn.NeuronUnit{Weights: make([]float64, len(patterns[0].Features)), Bias: bias, Lrate: learningRate}
-		var scores2 []float64 = v.RandomSubsamplingValidation(&neuron2, patterns, percentage, epochs, folds, shuffle)
+		var scores2 []float64 = v.RandomSubsamplingValidation(mn.PerceptronModel(&neuron2, epochs), patterns, percentage, folds, shuffle, rng)
 
 		log.WithFields(log.Fields{
 			"level":  "info",
@@ -102,8 +115,9 @@ func main() {
 		var epochs = 500
 		var folds  = 3
 
-		// Patterns initialization
-		var patterns, _ , mapped = mn.LoadPatternsFromCSVFile(filePath)
+		// Dataset initialization: patterns, with class names
+		var dataset, _ = mn.LoadDatasetFromCSVFile(filePath)
+		var patterns, mapped = dataset.Patterns, dataset.Classes()
 
 		//input  layer : 4 neuron, represents the feature of Iris, more in general dimensions of pattern
 		//hidden layer : 3 neuron, activation using sigmoid, number of neuron in hidden level
@@ -112,14 +126,18 @@ func main() {
 		var layers []int = []int{len(patterns[0].Features), 20, len(mapped)}
 
 		//Multilayer perceptron model, with one hidden layer.
-		var mlp mn.MultiLayerNetwork = mn.PrepareMLPNet(layers, learningRate, mn.SigmoidalTransfer, mn.SigmoidalTransferDerivate)
+		var mlp mn.MultiLayerNetwork = mn.PrepareMLPNet(layers, learningRate, mn.Sigmoid, rng)
+
+		// features scaled between 0 and 1, fitted on training patterns of each fold
+		mlp.Pipeline = mn.NewPipeline(mn.MinMaxScaler())
 
 		// compute scores for each folds execution
-		var scores = v.MLPKFoldValidation(&mlp, patterns, epochs, folds, shuffle, mapped)
+		var scores = v.KFoldValidation(mn.MLPModel(&mlp, mapped, epochs), patterns, folds, shuffle, rng)
 
 		// use simpler validation
-		var mlp2 mn.MultiLayerNetwork = mn.PrepareMLPNet(layers, learningRate, mn.SigmoidalTransfer, mn.SigmoidalTransferDerivate)
-		var scores2 = v.MLPRandomSubsamplingValidation(&mlp2, patterns, percentage, epochs, folds, shuffle, mapped)
+		var mlp2 mn.MultiLayerNetwork = mn.PrepareMLPNet(layers, learningRate, mn.Sigmoid, rng)
+		mlp2.Pipeline = mn.NewPipeline(mn.MinMaxScaler())
+		var scores2 = v.RandomSubsamplingValidation(mn.MLPModel(&mlp2, mapped, epochs), patterns, percentage, folds, shuffle, rng)
 
 		log.WithFields(log.Fields{
 			"level":  "info",
@@ -133,6 +151,25 @@ func main() {
 			"scores": scores2,
 		}).Info("Scores reached: ", scores2)
 
+		// predict class name of each pattern, when tested
+		var mlp3 mn.MultiLayerNetwork = mn.PrepareMLPNet(layers, learningRate, mn.Sigmoid, rng)
+		mlp3.Pipeline = mn.NewPipeline(mn.MinMaxScaler())
+		var predictions = v.KFoldPredictions(mn.MLPModel(&mlp3, mapped, epochs), dataset, folds, shuffle, rng)
+
+		log.WithFields(log.Fields{
+			"level":       "info",
+			"place":       "main",
+			"predictions": predictions[:5],
+		}).Info("First predictions: ", predictions[:5])
+
+		// classification report of predictions pooled over stratified folds
+		var mlp4 mn.MultiLayerNetwork = mn.PrepareMLPNet(layers, learningRate, mn.Sigmoid, rng)
+		mlp4.Pipeline = mn.NewPipeline(mn.MinMaxScaler())
+		var evaluation = v.CrossValidationEvaluation(mn.MLPModel(&mlp4, mapped, epochs), patterns, v.StratifiedKFold(folds, shuffle), rng)
+		evaluation.Decode = dataset.Decode
+
+		fmt.Print(evaluation.Report())
+
 	}
 
 	// #############################################################################################################
@@ -153,13 +190,12 @@ func main() {
 
 		// single layer neuron parameters
 		var learningRate = 0.01
-		var shuffle = 1
 
 		// training parameters
 		var epochs = 500
 
 		// Patterns initialization
-		var patterns = mn.CreateRandomPatternArray(8, 30)
+		var patterns = mn.CreateRandomPatternArray(8, 30, rng)
 
 		//log.Info(patterns[0].Features[:int(len(patterns[0].Features)/2)])
 		//n := mu.ConvertBinToInt(patterns[0].Features[:int(len(patterns[0].Features)/2)])
@@ -175,10 +211,10 @@ func main() {
 		var mlp mn.MultiLayerNetwork =
 				mn.PrepareElmanNet(len(patterns[0].Features)+10,
 				10, len(patterns[0].MultipleExpectation), learningRate,
-				mn.SigmoidalTransfer, mn.SigmoidalTransferDerivate)
+				mn.Sigmoid, rng)
 
 		// compute scores for each folds execution
-		var mean, _ = v.RNNValidation(&mlp, patterns, epochs, shuffle)
+		var mean, _ = v.ResubstitutionValidation(mn.ElmanModel(&mlp, epochs), patterns)
 
 		log.WithFields(log.Fields{
 			"level":  "info",
@@ -0,0 +1,240 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	// internal import
+	mu "github.com/made2591/go-perceptron-go/util"
+)
+
+// probaEpsilon represents how far from 0 and 1 probabilities are clipped by log-loss
+const probaEpsilon = 1e-15
+
+// #######################################################################################
+
+// LogLoss returns the mean negative log-likelihood of actual classes under the raw outputs of a model
+// (see neural.Model.PredictProba): proba[i] are the outputs for the i-th pattern, normalized to sum 1, and
+// actual[i] is the index of the output of its class. A single output is the probability of class 1 against 0.
+// Probabilities are clipped away from 0, so that a single confident error does not make the loss infinite.
+func LogLoss(actual []float64, proba [][]float64) (float64, error) {
+
+	if err := checkProba(actual, proba); err != nil {
+		return 0, err
+	}
+
+	loss := 0.0
+	for i := range actual {
+		k, err := classIndex(actual[i], len(proba[i]))
+		if err != nil {
+			return 0, err
+		}
+		p := math.Min(math.Max(probability(proba[i], k), probaEpsilon), 1-probaEpsilon)
+		loss -= math.Log(p)
+	}
+	return loss / float64(len(actual)), nil
+
+}
+
+// ROCAUC returns the area under the ROC curve of raw outputs of a model, with actual classes as in LogLoss:
+// 1 is perfect ranking of patterns, 0.5 is chance. With one or two outputs it is the area of class 1,
+// otherwise the area of each class against the others, averaged as specified by a (Micro pools all outputs).
+// Classes without patterns, or with all of them, are left out of averages.
+func ROCAUC(actual []float64, proba [][]float64, a Average) (float64, error) {
+	return oneVsRest(actual, proba, a, BinaryROCAUC)
+}
+
+// PRAUC returns the area under the precision-recall curve of raw outputs of a model, computed as average
+// precision, with classes and averages as in ROCAUC. Unlike ROC-AUC, it reflects how rare a class is:
+// chance is its frequency.
+func PRAUC(actual []float64, proba [][]float64, a Average) (float64, error) {
+	return oneVsRest(actual, proba, a, BinaryPRAUC)
+}
+
+// BinaryROCAUC returns the area under the ROC curve of scores of patterns, where positive[i] is true if the
+// i-th pattern is in the class: the probability that a random positive pattern scores more than a random
+// negative one (ties count half).
+// It returns an error if there are no positive or no negative patterns.
+func BinaryROCAUC(positive []bool, scores []float64) (float64, error) {
+
+	tps, fps, err := curve(positive, scores)
+	if err != nil {
+		return 0, err
+	}
+	p, n := tps[len(tps)-1], fps[len(fps)-1]
+	if p == 0 || n == 0 {
+		return 0, fmt.Errorf("metrics: ROC curve of %d positive and %d negative patterns", p, n)
+	}
+
+	// trapezoids between thresholds
+	area, tp, fp := 0.0, 0, 0
+	for t := range tps {
+		area += float64(fps[t]-fp) * float64(tps[t]+tp) / 2
+		tp, fp = tps[t], fps[t]
+	}
+	return area / (float64(p) * float64(n)), nil
+
+}
+
+// BinaryPRAUC returns the average precision of scores of patterns, with positive as in BinaryROCAUC:
+// the precision at each threshold weighted by the recall gained at it.
+// It returns an error if there are no positive patterns.
+func BinaryPRAUC(positive []bool, scores []float64) (float64, error) {
+
+	tps, fps, err := curve(positive, scores)
+	if err != nil {
+		return 0, err
+	}
+	p := tps[len(tps)-1]
+	if p == 0 {
+		return 0, fmt.Errorf("metrics: precision-recall curve without positive patterns")
+	}
+
+	area, tp := 0.0, 0
+	for t := range tps {
+		area += float64(tps[t]-tp) / float64(p) * ratio(tps[t], tps[t]+fps[t])
+		tp = tps[t]
+	}
+	return area, nil
+
+}
+
+// curve returns the number of true and false positives with score at least each distinct score,
+// in decreasing order of score.
+func curve(positive []bool, scores []float64) (tps []int, fps []int, err error) {
+
+	if len(positive) != len(scores) || len(scores) == 0 {
+		return nil, nil, fmt.Errorf("metrics: %d scores of %d patterns", len(scores), len(positive))
+	}
+	order := make([]int, len(scores))
+	for i := range order {
+		if math.IsNaN(scores[i]) {
+			return nil, nil, fmt.Errorf("metrics: NaN score of pattern %d", i)
+		}
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	tp, fp := 0, 0
+	for r, i := range order {
+		if positive[i] {
+			tp++
+		} else {
+			fp++
+		}
+		if r == len(order)-1 || scores[order[r+1]] != scores[i] {
+			tps, fps = append(tps, tp), append(fps, fp)
+		}
+	}
+	return tps, fps, nil
+
+}
+
+// oneVsRest computes binary measure f of outputs of a model (see ROCAUC).
+func oneVsRest(actual []float64, proba [][]float64, a Average, f func([]bool, []float64) (float64, error)) (float64, error) {
+
+	if err := checkProba(actual, proba); err != nil {
+		return 0, err
+	}
+	n := len(proba[0])
+	classes := make([]int, len(actual))
+	for i := range actual {
+		k, err := classIndex(actual[i], n)
+		if err != nil {
+			return 0, err
+		}
+		classes[i] = k
+	}
+
+	// binary: scores of class 1
+	if n <= 2 {
+		positive, scores := make([]bool, len(actual)), make([]float64, len(actual))
+		for i := range actual {
+			positive[i], scores[i] = classes[i] == 1, probability(proba[i], 1)
+		}
+		return f(positive, scores)
+	}
+
+	if a == Micro {
+		positive, scores := make([]bool, 0, n*len(actual)), make([]float64, 0, n*len(actual))
+		for i := range actual {
+			for k := 0; k < n; k++ {
+				positive, scores = append(positive, classes[i] == k), append(scores, probability(proba[i], k))
+			}
+		}
+		return f(positive, scores)
+	}
+
+	sum, weights := 0.0, 0.0
+	for k := 0; k < n; k++ {
+		positive, scores, support := make([]bool, len(actual)), make([]float64, len(actual)), 0
+		for i := range actual {
+			if positive[i], scores[i] = classes[i] == k, probability(proba[i], k); positive[i] {
+				support++
+			}
+		}
+		v, err := f(positive, scores)
+		if err != nil {
+			continue
+		}
+		w := 1.0
+		if a == Weighted {
+			w = float64(support)
+		}
+		sum, weights = sum+w*v, weights+w
+	}
+	if weights == 0 {
+		return 0, fmt.Errorf("metrics: no class of %d outputs can be ranked against the others", n)
+	}
+	return sum / weights, nil
+
+}
+
+// checkProba returns an error if proba are not outputs of a model for each of actual classes.
+func checkProba(actual []float64, proba [][]float64) error {
+
+	if len(actual) != len(proba) || len(actual) == 0 {
+		return fmt.Errorf("metrics: %d actual classes and %d outputs", len(actual), len(proba))
+	}
+	for i := range proba {
+		if len(proba[i]) == 0 || len(proba[i]) != len(proba[0]) {
+			return fmt.Errorf("metrics: %d outputs for pattern %d, %d for pattern 0", len(proba[i]), i, len(proba[0]))
+		}
+	}
+	return nil
+
+}
+
+// classIndex returns the index of the output of class v among n outputs (classes 0 and 1 for a single output).
+func classIndex(v float64, n int) (int, error) {
+
+	k := int(math.Round(v))
+	if !mu.SameClass(float64(k), v) || k < 0 || k >= n && (n > 1 || k > 1) {
+		return 0, fmt.Errorf("metrics: %v is not the index of one of %d outputs", v, n)
+	}
+	return k, nil
+
+}
+
+// probability returns the probability of the k-th class in outputs y, normalized to sum 1.
+// A single output is the probability of class 1.
+func probability(y []float64, k int) float64 {
+
+	if len(y) == 1 {
+		if k == 1 {
+			return y[0]
+		}
+		return 1 - y[0]
+	}
+
+	s := 0.0
+	for _, v := range y {
+		s += v
+	}
+	if s > 0 {
+		return y[k] / s
+	}
+	return y[k]
+
+}
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+// rankedProba represents outputs for 4 patterns of classes rankedActual, exact in binary so that ties are ties:
+// class 0 and 1 are ranked first, class 2 scores as much as one negative pattern
+var rankedProba = [][]float64{{0.5, 0.375, 0.125}, {0.25, 0.5, 0.25}, {0.125, 0.125, 0.75}, {0.375, 0.375, 0.25}}
+
+// rankedActual represents classes of patterns of rankedProba
+var rankedActual = []float64{0, 1, 2, 2}
+
+// TestLogLoss checks log-loss against values computed by hand.
+func TestLogLoss(t *testing.T) {
+
+	cases := []struct {
+		name   string
+		actual []float64
+		proba  [][]float64
+		want   float64
+	}{
+		{"three outputs", []float64{0, 1, 2}, [][]float64{{0.7, 0.2, 0.1}, {0.1, 0.8, 0.1}, {0.2, 0.2, 0.6}},
+			-(math.Log(0.7) + math.Log(0.8) + math.Log(0.6)) / 3},
+		{"normalized outputs", []float64{0}, [][]float64{{2, 2}}, math.Ln2},
+		{"single output", []float64{1, 0}, [][]float64{{0.9}, {0.2}}, -(math.Log(0.9) + math.Log(0.8)) / 2},
+		{"clipped", []float64{1}, [][]float64{{1, 0}}, -math.Log(probaEpsilon)},
+	}
+	for _, c := range cases {
+		if loss, err := LogLoss(c.actual, c.proba); err != nil || math.Abs(loss-c.want) > metricsTolerance {
+			t.Errorf("%s: log-loss is %v (%v), want %v", c.name, loss, err, c.want)
+		}
+	}
+
+}
+
+// TestBinaryAUC checks areas under ROC and precision-recall curves of scores against values computed by hand.
+func TestBinaryAUC(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		positive []bool
+		scores   []float64
+		roc, pr  float64
+	}{
+		// 5 of 6 pairs ranked right; precision 1 at recall 0.5, 2/3 at recall 1
+		{"ranked", []bool{true, false, true, false, false}, []float64{0.9, 0.8, 0.4, 0.3, 0.1}, 5.0 / 6, 0.5 + 1.0/3},
+		{"perfect", []bool{false, true, true}, []float64{0.2, 0.7, 0.5}, 1, 1},
+		{"reversed", []bool{false, true}, []float64{0.9, 0.1}, 0, 0.5},
+		// one tie in 4 pairs counts half; both patterns of the tie enter the curve together
+		{"tie", []bool{true, false, true, false}, []float64{0.9, 0.5, 0.5, 0.1}, 3.5 / 4, 0.5 + 0.5*2/3},
+		// a single threshold: chance
+		{"all tied", []bool{true, false, false, false}, []float64{0.5, 0.5, 0.5, 0.5}, 0.5, 0.25},
+	}
+	for _, c := range cases {
+		if roc, err := BinaryROCAUC(c.positive, c.scores); err != nil || math.Abs(roc-c.roc) > metricsTolerance {
+			t.Errorf("%s: ROC-AUC is %v (%v), want %v", c.name, roc, err, c.roc)
+		}
+		if pr, err := BinaryPRAUC(c.positive, c.scores); err != nil || math.Abs(pr-c.pr) > metricsTolerance {
+			t.Errorf("%s: PR-AUC is %v (%v), want %v", c.name, pr, err, c.pr)
+		}
+	}
+
+	// only positive patterns: precision is always 1, nothing to rank against
+	if roc, err := BinaryROCAUC([]bool{true, true}, []float64{0.1, 0.9}); err == nil {
+		t.Errorf("ROC-AUC without negative patterns is %v", roc)
+	}
+	if pr, err := BinaryPRAUC([]bool{true, true}, []float64{0.1, 0.9}); err != nil || pr != 1 {
+		t.Errorf("PR-AUC without negative patterns is %v (%v), want 1", pr, err)
+	}
+
+}
+
+// TestOneVsRestAUC checks averages of areas under ROC and precision-recall curves of classes against values
+// computed by hand, and classes without patterns.
+func TestOneVsRestAUC(t *testing.T) {
+
+	cases := []struct {
+		name    string
+		a       Average
+		roc, pr float64
+	}{
+		// class 2 has ROC-AUC 3.5/4 and PR-AUC 5/6, classes 0 and 1 are ranked first
+		{"macro", Macro, (2 + 0.875) / 3, (2 + 5.0/6) / 3},
+		{"weighted", Weighted, (2 + 2*0.875) / 4, (2 + 2*5.0/6) / 4},
+		// 4 positive and 8 negative outputs: the lowest positive ties with 2 negatives and beats 3,
+		// after 3 more negatives
+		{"micro", Micro, (3*8 + 4.0) / 32, 0.75 + 0.25*4/9},
+	}
+	for _, c := range cases {
+		if roc, err := ROCAUC(rankedActual, rankedProba, c.a); err != nil || math.Abs(roc-c.roc) > metricsTolerance {
+			t.Errorf("%s: ROC-AUC is %v (%v), want %v", c.name, roc, err, c.roc)
+		}
+		if pr, err := PRAUC(rankedActual, rankedProba, c.a); err != nil || math.Abs(pr-c.pr) > metricsTolerance {
+			t.Errorf("%s: PR-AUC is %v (%v), want %v", c.name, pr, err, c.pr)
+		}
+	}
+
+	// class 1 has no patterns: left out of averages of classes 0 and 2
+	actual, proba := []float64{0, 2, 2, 0}, [][]float64{{0.5, 0.375, 0.125}, {0.25, 0.125, 0.625}, {0.375, 0.25, 0.375}, {0.25, 0.25, 0.5}}
+	for _, a := range []Average{Macro, Weighted} {
+		// positive 0.5 and 0.25 of class 0 against 0.375 and 0.25 rank 2.5 of 4 pairs, 0.625 and 0.375 of class 2
+		// against 0.5 and 0.125 rank 3 of 4
+		if roc, err := ROCAUC(actual, proba, a); err != nil || math.Abs(roc-(0.625+0.75)/2) > metricsTolerance {
+			t.Errorf("%s: ROC-AUC without patterns of class 1 is %v (%v), want %v", a, roc, err, (0.625+0.75)/2)
+		}
+	}
+
+	// two outputs: area of class 1
+	if roc, err := ROCAUC([]float64{0, 1, 1}, [][]float64{{0.8, 0.2}, {0.3, 0.7}, {0.9, 0.1}}, Macro); err != nil || roc != 0.5 {
+		t.Errorf("ROC-AUC of two outputs is %v (%v), want 0.5", roc, err)
+	}
+
+	// a single class: no class can be ranked against the others
+	single := []float64{1, 1, 1}
+	binary, three := [][]float64{{0.4, 0.6}, {0.3, 0.7}, {0.2, 0.8}}, [][]float64{{0.1, 0.6, 0.3}, {0.2, 0.7, 0.1}, {0.3, 0.5, 0.2}}
+	for _, a := range []Average{Macro, Micro, Weighted} {
+		if roc, err := ROCAUC(single, binary, a); err == nil {
+			t.Errorf("%s: ROC-AUC of a single class of two outputs is %v", a, roc)
+		}
+	}
+	for _, a := range []Average{Macro, Weighted} {
+		if roc, err := ROCAUC(single, three, a); err == nil {
+			t.Errorf("%s: ROC-AUC of a single class of three outputs is %v", a, roc)
+		}
+	}
+	// outputs of other classes are the negatives pooled by micro averages
+	if roc, err := ROCAUC(single, three, Micro); err != nil || roc != 1 {
+		t.Errorf("micro ROC-AUC of a single class is %v (%v), want 1", roc, err)
+	}
+	// only classes without patterns are left out of PR-AUC
+	if pr, err := PRAUC(single, three, Macro); err != nil || pr != 1 {
+		t.Errorf("PR-AUC of a single class is %v (%v), want 1", pr, err)
+	}
+
+}
+
+// TestCurveErrors checks that measures of outputs return an error on inputs they can not rank.
+func TestCurveErrors(t *testing.T) {
+
+	cases := map[string]struct {
+		actual []float64
+		proba  [][]float64
+	}{
+		"different lengths":        {[]float64{0, 1}, [][]float64{{0.5, 0.5}}},
+		"no patterns":              {nil, nil},
+		"different output lengths": {[]float64{0, 1}, [][]float64{{0.5, 0.5}, {0.1, 0.2, 0.7}}},
+		"no outputs":               {[]float64{0}, [][]float64{{}}},
+		"not an index":             {[]float64{0, 0.5}, [][]float64{{0.5, 0.5}, {0.5, 0.5}}},
+		"index out of range":       {[]float64{0, 3}, [][]float64{{0.2, 0.3, 0.5}, {0.2, 0.3, 0.5}}},
+		"negative index":           {[]float64{0, -1}, [][]float64{{0.5}, {0.5}}},
+	}
+	for name, c := range cases {
+		if loss, err := LogLoss(c.actual, c.proba); err == nil {
+			t.Errorf("%s: log-loss is %v", name, loss)
+		}
+		if roc, err := ROCAUC(c.actual, c.proba, Macro); err == nil {
+			t.Errorf("%s: ROC-AUC is %v", name, roc)
+		}
+		if pr, err := PRAUC(c.actual, c.proba, Micro); err == nil {
+			t.Errorf("%s: PR-AUC is %v", name, pr)
+		}
+	}
+
+	if roc, err := BinaryROCAUC([]bool{true, false}, []float64{0.5, math.NaN()}); err == nil {
+		t.Errorf("ROC-AUC of a NaN score is %v", roc)
+	}
+	if pr, err := BinaryPRAUC([]bool{false, false}, []float64{0.5, 0.1}); err == nil {
+		t.Errorf("PR-AUC without positive patterns is %v", pr)
+	}
+
+}
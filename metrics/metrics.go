@@ -0,0 +1,268 @@
+// Metrics provides measures of the quality of classifiers, from their predictions and their raw outputs.
+// Unlike scores of validation, that are percentages, measures are fractions in [0, 1].
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	// internal import
+	mu "github.com/made2591/go-perceptron-go/util"
+)
+
+// Average represents how the scores of classes are combined in a single score.
+type Average int
+
+const (
+	// Macro averages the scores of classes with the same weight
+	Macro Average = iota
+	// Micro computes the score on the counts of all classes together
+	Micro
+	// Weighted averages the scores of classes weighted by their support
+	Weighted
+)
+
+// Scores represents precision, recall and F1 score of a class, or an average of them.
+type Scores struct {
+
+	// Precision represents the fraction of patterns predicted in the class that are in the class
+	Precision float64
+	// Recall represents the fraction of patterns in the class that are predicted in the class
+	Recall float64
+	// F1 represents the harmonic mean of precision and recall
+	F1 float64
+	// Support represents the number of patterns in the class (of all patterns, for an average)
+	Support int
+
+}
+
+// ConfusionMatrix represents counts of patterns by actual class (rows) and by predicted class (columns).
+type ConfusionMatrix struct {
+
+	// Classes represents values of classes, in increasing order, of rows and columns
+	Classes []float64
+	// Counts represents the number of patterns of class Classes[i] predicted as Classes[j], in Counts[i][j]
+	Counts [][]int
+
+}
+
+// #######################################################################################
+
+// String returns the name of an average, as in reports.
+func (a Average) String() string {
+
+	switch a {
+	case Macro:
+		return "macro"
+	case Micro:
+		return "micro"
+	case Weighted:
+		return "weighted"
+	}
+	return fmt.Sprintf("Average(%d)", int(a))
+
+}
+
+// NewConfusionMatrix counts patterns whose actual class is actual[i] and predicted class is predicted[i].
+// Classes are all the values found in both, compared up to rounding errors (see util.SameClass).
+// It returns an error if slices have different length, are empty or contain NaN.
+func NewConfusionMatrix(actual []float64, predicted []float64) (*ConfusionMatrix, error) {
+
+	if len(actual) != len(predicted) {
+		return nil, fmt.Errorf("metrics: %d actual classes and %d predictions", len(actual), len(predicted))
+	}
+	if len(actual) == 0 {
+		return nil, fmt.Errorf("metrics: no predictions")
+	}
+
+	values := make([]float64, 0, 2*len(actual))
+	values = append(append(values, actual...), predicted...)
+	sort.Float64s(values)
+	if math.IsNaN(values[0]) {
+		return nil, fmt.Errorf("metrics: NaN is not a class")
+	}
+
+	c := &ConfusionMatrix{}
+	for _, v := range values {
+		if len(c.Classes) == 0 || !mu.SameClass(c.Classes[len(c.Classes)-1], v) {
+			c.Classes = append(c.Classes, v)
+		}
+	}
+	c.Counts = make([][]int, len(c.Classes))
+	for i := range c.Counts {
+		c.Counts[i] = make([]int, len(c.Classes))
+	}
+	for i := range actual {
+		c.Counts[c.index(actual[i])][c.index(predicted[i])]++
+	}
+	return c, nil
+
+}
+
+// index returns the index of the class of value v, or -1 if it is not a class.
+func (c *ConfusionMatrix) index(v float64) int {
+
+	i := sort.SearchFloat64s(c.Classes, v)
+	for _, j := range []int{i - 1, i} {
+		if j >= 0 && j < len(c.Classes) && mu.SameClass(c.Classes[j], v) {
+			return j
+		}
+	}
+	return -1
+
+}
+
+// Len returns the number of classes.
+func (c *ConfusionMatrix) Len() int {
+	return len(c.Classes)
+}
+
+// Total returns the number of patterns.
+func (c *ConfusionMatrix) Total() int {
+
+	n := 0
+	for i := range c.Counts {
+		for j := range c.Counts[i] {
+			n += c.Counts[i][j]
+		}
+	}
+	return n
+
+}
+
+// sums returns the number of patterns of each class (sums of rows) and predicted in each class (sums of columns).
+func (c *ConfusionMatrix) sums() (actual []int, predicted []int) {
+
+	actual, predicted = make([]int, c.Len()), make([]int, c.Len())
+	for i := range c.Counts {
+		for j, n := range c.Counts[i] {
+			actual[i] += n
+			predicted[j] += n
+		}
+	}
+	return actual, predicted
+
+}
+
+// Accuracy returns the fraction of patterns whose class is predicted correctly.
+func (c *ConfusionMatrix) Accuracy() float64 {
+
+	correct := 0
+	for i := range c.Counts {
+		correct += c.Counts[i][i]
+	}
+	return ratio(correct, c.Total())
+
+}
+
+// Class returns precision, recall and F1 score of the i-th class: scores without patterns to divide by are 0.
+func (c *ConfusionMatrix) Class(i int) Scores {
+
+	actual, predicted := c.sums()
+	return scores(c.Counts[i][i], predicted[i], actual[i])
+
+}
+
+// Averaged returns precision, recall and F1 score averaged over classes as specified by a.
+// Micro averages are all equal to the accuracy, as each pattern has a single class.
+func (c *ConfusionMatrix) Averaged(a Average) Scores {
+
+	if a == Micro {
+		correct := 0
+		for i := range c.Counts {
+			correct += c.Counts[i][i]
+		}
+		return scores(correct, c.Total(), c.Total())
+	}
+
+	var s Scores
+	total := float64(c.Total())
+	for i := range c.Classes {
+		ci := c.Class(i)
+		w := 1 / float64(c.Len())
+		if a == Weighted {
+			w = float64(ci.Support) / total
+		}
+		s.Precision += w * ci.Precision
+		s.Recall += w * ci.Recall
+		s.F1 += w * ci.F1
+		s.Support += ci.Support
+	}
+	return s
+
+}
+
+// BalancedAccuracy returns the mean recall of classes with patterns, that is not biased toward larger classes.
+func (c *ConfusionMatrix) BalancedAccuracy() float64 {
+
+	actual, _ := c.sums()
+	sum, n := 0.0, 0
+	for i := range c.Classes {
+		if actual[i] > 0 {
+			sum += ratio(c.Counts[i][i], actual[i])
+			n++
+		}
+	}
+	return sum / float64(n)
+
+}
+
+// CohenKappa returns the agreement of predictions with actual classes beyond the agreement expected by chance:
+// 1 is complete agreement, 0 is chance. It is NaN if chance agreement is complete (a single class).
+func (c *ConfusionMatrix) CohenKappa() float64 {
+
+	actual, predicted := c.sums()
+	total := float64(c.Total())
+	expected := 0.0
+	for i := range c.Classes {
+		expected += float64(actual[i]) * float64(predicted[i]) / (total * total)
+	}
+	return (c.Accuracy() - expected) / (1 - expected)
+
+}
+
+// MatthewsCorrelation returns the correlation between predictions and actual classes, generalized to more than
+// two classes: 1 is perfect prediction, 0 is chance, -1 is complete disagreement (for two classes).
+// It is 0 if either all patterns or all predictions are of a single class.
+func (c *ConfusionMatrix) MatthewsCorrelation() float64 {
+
+	actual, predicted := c.sums()
+	total := float64(c.Total())
+	correct, cov, actualSq, predictedSq := 0.0, 0.0, 0.0, 0.0
+	for i := range c.Classes {
+		correct += float64(c.Counts[i][i])
+		cov += float64(actual[i]) * float64(predicted[i])
+		actualSq += float64(actual[i]) * float64(actual[i])
+		predictedSq += float64(predicted[i]) * float64(predicted[i])
+	}
+
+	d := math.Sqrt((total*total - predictedSq) * (total*total - actualSq))
+	if d == 0 {
+		return 0
+	}
+	return (correct*total - cov) / d
+
+}
+
+// scores returns precision, recall and F1 score of tp true positives out of predicted positives and
+// actual positives.
+func scores(tp int, predicted int, actual int) Scores {
+
+	s := Scores{Precision: ratio(tp, predicted), Recall: ratio(tp, actual), Support: actual}
+	if s.Precision+s.Recall > 0 {
+		s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+	}
+	return s
+
+}
+
+// ratio returns n / d, or 0 if d is 0.
+func ratio(n int, d int) float64 {
+
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// metricsTolerance represents the largest difference between measures and the ones computed by hand
+const metricsTolerance = 1e-12
+
+// sameScores returns true if scores are equal to want, up to metricsTolerance.
+func sameScores(s Scores, want Scores) bool {
+	return math.Abs(s.Precision-want.Precision) <= metricsTolerance && math.Abs(s.Recall-want.Recall) <= metricsTolerance &&
+		math.Abs(s.F1-want.F1) <= metricsTolerance && s.Support == want.Support
+}
+
+// TestConfusionMatrix checks counts, scores of each class and averages against values computed by hand.
+func TestConfusionMatrix(t *testing.T) {
+
+	// 4 patterns of class 0, 4 of class 1, 2 of class 2: 7 of 10 predicted right
+	c, err := NewConfusionMatrix([]float64{0, 0, 0, 0, 1, 1, 1, 1, 2, 2}, []float64{0, 0, 0, 1, 0, 1, 1, 2, 2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]int{{3, 1, 0}, {1, 2, 1}, {0, 0, 2}}; !reflect.DeepEqual(c.Classes, []float64{0, 1, 2}) || !reflect.DeepEqual(c.Counts, want) {
+		t.Fatalf("classes %v and counts %v, want [0 1 2] and %v", c.Classes, c.Counts, want)
+	}
+	if c.Len() != 3 || c.Total() != 10 || c.Accuracy() != 0.7 {
+		t.Errorf("%d classes, %d patterns, accuracy %v, want 3, 10 and 0.7", c.Len(), c.Total(), c.Accuracy())
+	}
+
+	cases := []struct {
+		name string
+		s    Scores
+		want Scores
+	}{
+		{"class 0", c.Class(0), Scores{0.75, 0.75, 0.75, 4}},
+		{"class 1", c.Class(1), Scores{2.0 / 3, 0.5, 4.0 / 7, 4}},
+		{"class 2", c.Class(2), Scores{2.0 / 3, 1, 0.8, 2}},
+		{"macro", c.Averaged(Macro), Scores{25.0 / 36, 0.75, (0.75 + 4.0/7 + 0.8) / 3, 10}},
+		{"micro", c.Averaged(Micro), Scores{0.7, 0.7, 0.7, 10}},
+		// weights 0.4, 0.4 and 0.2: weighted recall is the accuracy
+		{"weighted", c.Averaged(Weighted), Scores{0.7, 0.7, 0.46 + 1.6/7, 10}},
+	}
+	for _, cs := range cases {
+		if !sameScores(cs.s, cs.want) {
+			t.Errorf("%s: scores %+v, want %+v", cs.name, cs.s, cs.want)
+		}
+	}
+
+}
+
+// TestAgreementMeasures checks balanced accuracy, Cohen's kappa and Matthews correlation against values computed
+// by hand, including a single class and a class never in actual classes.
+func TestAgreementMeasures(t *testing.T) {
+
+	cases := []struct {
+		name                      string
+		actual, predicted         []float64
+		balanced, kappa, matthews float64
+	}{
+		// expected agreement (4*4 + 4*3 + 2*3) / 100 = 0.34; (7*10 - 34) / sqrt((100 - 34) * (100 - 36))
+		{"three classes", []float64{0, 0, 0, 0, 1, 1, 1, 1, 2, 2}, []float64{0, 0, 0, 1, 0, 1, 1, 2, 2, 2},
+			0.75, 0.36 / 0.66, 36 / math.Sqrt(66*64)},
+		{"all wrong", []float64{0, 1}, []float64{1, 0}, 0, -1, -1},
+		// class 2 is only predicted, left out of balanced accuracy: (2/3 - 1/3) / (1 - 1/3) and (2*3 - 3) / sqrt(6*4)
+		{"zero support", []float64{0, 0, 1}, []float64{0, 2, 1}, 0.75, 0.5, 3 / math.Sqrt(24)},
+		// chance agreement is 1: kappa is undefined, Matthews correlation is 0
+		{"single class", []float64{1, 1, 1}, []float64{1, 1, 1}, 1, math.NaN(), 0},
+	}
+
+	for _, cs := range cases {
+		c, err := NewConfusionMatrix(cs.actual, cs.predicted)
+		if err != nil {
+			t.Fatalf("%s: %v", cs.name, err)
+		}
+		measures := []struct {
+			name      string
+			got, want float64
+		}{
+			{"balanced accuracy", c.BalancedAccuracy(), cs.balanced},
+			{"Cohen's kappa", c.CohenKappa(), cs.kappa},
+			{"Matthews correlation", c.MatthewsCorrelation(), cs.matthews},
+		}
+		for _, m := range measures {
+			if math.IsNaN(m.want) != math.IsNaN(m.got) || math.Abs(m.got-m.want) > metricsTolerance {
+				t.Errorf("%s: %s is %v, want %v", cs.name, m.name, m.got, m.want)
+			}
+		}
+	}
+
+	// a class with no patterns and no predictions scores 0, not NaN
+	c, _ := NewConfusionMatrix([]float64{0, 0, 1}, []float64{0, 2, 1})
+	if s := c.Class(2); !sameScores(s, Scores{0, 0, 0, 0}) {
+		t.Errorf("class only predicted has scores %+v", s)
+	}
+	if s := c.Averaged(Macro); !sameScores(s, Scores{2.0 / 3, 0.5, (2.0/3 + 1) / 3, 3}) {
+		t.Errorf("macro average with a class only predicted is %+v", s)
+	}
+
+}
+
+// TestConfusionMatrixErrors checks that NewConfusionMatrix returns an error on classes it can not count.
+func TestConfusionMatrixErrors(t *testing.T) {
+
+	cases := map[string][2][]float64{
+		"different lengths": {{0, 1}, {0}},
+		"no patterns":       {{}, {}},
+		"NaN actual":        {{0, math.NaN()}, {0, 1}},
+		"NaN predicted":     {{0, 1}, {math.NaN(), 1}},
+	}
+	for name, cs := range cases {
+		if c, err := NewConfusionMatrix(cs[0], cs[1]); err == nil {
+			t.Errorf("%s: confusion matrix %v without error", name, c.Counts)
+		}
+	}
+
+	// classes equal up to rounding errors are merged
+	tenth := 0.1
+	c, err := NewConfusionMatrix([]float64{1, tenth + 0.2}, []float64{1, 0.3})
+	if err != nil || c.Len() != 2 || c.Accuracy() != 1 {
+		t.Errorf("classes 0.1 + 0.2 and 0.3 are different: %v, %v", c, err)
+	}
+
+}
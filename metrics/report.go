@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	// internal import
+	mn "github.com/made2591/go-perceptron-go/model/neural"
+)
+
+// Evaluation represents the predictions of a model on test patterns, from which all measures are computed.
+type Evaluation struct {
+
+	// Actual represents the class of each pattern (its SingleExpectation)
+	Actual []float64
+	// Predicted represents the class predicted for each pattern
+	Predicted []float64
+	// Proba represents raw outputs of the model for each pattern (see neural.Model.PredictProba)
+	Proba [][]float64
+	// Decode returns the name of a class in reports (e.g. neural.Dataset.Decode), nil prints class values
+	Decode func(class float64) string
+
+}
+
+// #######################################################################################
+
+// Evaluate predicts each one of patterns with model, already trained.
+// It returns the evaluation of its predictions.
+func Evaluate(model mn.Model, patterns []mn.Pattern) *Evaluation {
+
+	e := &Evaluation{}
+	for i := range patterns {
+		e.Add(patterns[i].SingleExpectation, model.Predict(patterns[i].Features), model.PredictProba(patterns[i].Features))
+	}
+	return e
+
+}
+
+// Add appends the prediction of a pattern of class actual: its predicted class and raw outputs of the model.
+func (e *Evaluation) Add(actual float64, predicted float64, proba []float64) {
+
+	e.Actual = append(e.Actual, actual)
+	e.Predicted = append(e.Predicted, predicted)
+	e.Proba = append(e.Proba, proba)
+
+}
+
+// Merge appends predictions of other evaluations to e, e.g. to pool the folds of a cross validation.
+func (e *Evaluation) Merge(others ...*Evaluation) {
+
+	for _, o := range others {
+		e.Actual = append(e.Actual, o.Actual...)
+		e.Predicted = append(e.Predicted, o.Predicted...)
+		e.Proba = append(e.Proba, o.Proba...)
+	}
+
+}
+
+// Len returns the number of predictions.
+func (e *Evaluation) Len() int {
+	return len(e.Actual)
+}
+
+// ConfusionMatrix returns the confusion matrix of predictions, see NewConfusionMatrix.
+func (e *Evaluation) ConfusionMatrix() (*ConfusionMatrix, error) {
+	return NewConfusionMatrix(e.Actual, e.Predicted)
+}
+
+// LogLoss returns the log-loss of raw outputs, see LogLoss.
+func (e *Evaluation) LogLoss() (float64, error) {
+	return LogLoss(e.Actual, e.Proba)
+}
+
+// ROCAUC returns the area under the ROC curve of raw outputs, see ROCAUC.
+func (e *Evaluation) ROCAUC(a Average) (float64, error) {
+	return ROCAUC(e.Actual, e.Proba, a)
+}
+
+// PRAUC returns the area under the precision-recall curve of raw outputs, see PRAUC.
+func (e *Evaluation) PRAUC(a Average) (float64, error) {
+	return PRAUC(e.Actual, e.Proba, a)
+}
+
+// Report returns a printable classification report of predictions: see ConfusionMatrix.Report, followed by
+// log-loss, ROC-AUC and PR-AUC (macro averages) when raw outputs are probabilities of classes.
+func (e *Evaluation) Report() string {
+
+	c, err := e.ConfusionMatrix()
+	if err != nil {
+		return err.Error() + "\n"
+	}
+
+	var b bytes.Buffer
+	b.WriteString(c.Report(e.Decode))
+	if loss, err := e.LogLoss(); err == nil {
+		fmt.Fprintf(&b, "\n%-18s %8.4f\n", "log-loss", loss)
+		if auc, err := e.ROCAUC(Macro); err == nil {
+			fmt.Fprintf(&b, "%-18s %8.4f\n", "ROC-AUC", auc)
+		}
+		if auc, err := e.PRAUC(Macro); err == nil {
+			fmt.Fprintf(&b, "%-18s %8.4f\n", "PR-AUC", auc)
+		}
+	}
+	return b.String()
+
+}
+
+// Report returns a printable classification report: precision, recall, F1 score and support of each class,
+// accuracy, macro and weighted averages, balanced accuracy, Cohen's kappa and Matthews correlation, followed by
+// the confusion matrix. Classes are named by decode (nil prints class values).
+func (c *ConfusionMatrix) Report(decode func(class float64) string) string {
+
+	names := make([]string, c.Len())
+	for i, v := range c.Classes {
+		names[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		if decode != nil {
+			names[i] = decode(v)
+		}
+	}
+
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "\tprecision\trecall\tf1-score\tsupport\t")
+	fmt.Fprintln(w, "\t\t\t\t\t")
+	for i := range c.Classes {
+		s := c.Class(i)
+		fmt.Fprintf(w, "%s\t%.4f\t%.4f\t%.4f\t%d\t\n", names[i], s.Precision, s.Recall, s.F1, s.Support)
+	}
+	fmt.Fprintln(w, "\t\t\t\t\t")
+	fmt.Fprintf(w, "accuracy\t\t\t%.4f\t%d\t\n", c.Accuracy(), c.Total())
+	for _, a := range []Average{Macro, Weighted} {
+		s := c.Averaged(a)
+		fmt.Fprintf(w, "%s avg\t%.4f\t%.4f\t%.4f\t%d\t\n", a, s.Precision, s.Recall, s.F1, s.Support)
+	}
+	fmt.Fprintln(w, "\t\t\t\t\t")
+	fmt.Fprintf(w, "balanced accuracy\t\t\t%.4f\t\t\n", c.BalancedAccuracy())
+	fmt.Fprintf(w, "Cohen's kappa\t\t\t%.4f\t\t\n", c.CohenKappa())
+	fmt.Fprintf(w, "Matthews corr.\t\t\t%.4f\t\t\n", c.MatthewsCorrelation())
+	w.Flush()
+
+	b.WriteString("\n" + c.format(names))
+	return b.String()
+
+}
+
+// String returns the confusion matrix as a table, actual classes by row and predicted classes by column.
+func (c *ConfusionMatrix) String() string {
+
+	names := make([]string, c.Len())
+	for i, v := range c.Classes {
+		names[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return c.format(names)
+
+}
+
+// format returns the confusion matrix as a table, with classes named names.
+func (c *ConfusionMatrix) format(names []string) string {
+
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "actual \\ predicted\t")
+	for _, n := range names {
+		fmt.Fprintf(w, "%s\t", n)
+	}
+	fmt.Fprintln(w)
+	for i := range c.Counts {
+		fmt.Fprintf(w, "%s\t", names[i])
+		for _, n := range c.Counts[i] {
+			fmt.Fprintf(w, "%d\t", n)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+	return b.String()
+
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestEvaluation checks that merged evaluations pool predictions, and the measures of the reports computed by hand.
+func TestEvaluation(t *testing.T) {
+
+	a, b := &Evaluation{}, &Evaluation{}
+	a.Add(0, 0, []float64{0.8, 0.2})
+	b.Add(1, 1, []float64{0.3, 0.7})
+	b.Add(1, 0, []float64{0.6, 0.4})
+	a.Merge(b)
+	if a.Len() != 3 || !reflect.DeepEqual(a.Actual, []float64{0, 1, 1}) || !reflect.DeepEqual(a.Predicted, []float64{0, 1, 0}) || len(a.Proba) != 3 {
+		t.Fatalf("merged evaluation is %+v", a)
+	}
+	if loss, err := a.LogLoss(); err != nil || math.Abs(loss+(math.Log(0.8)+math.Log(0.7)+math.Log(0.4))/3) > metricsTolerance {
+		t.Errorf("log-loss of merged evaluation is %v (%v)", loss, err)
+	}
+
+	c, err := a.ConfusionMatrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, want := c.String(), "  actual \\ predicted  0  1\n                   0  1  0\n                   1  1  1\n"; s != want {
+		t.Errorf("confusion matrix is\n%s\nwant\n%s", s, want)
+	}
+
+	// classes named by Decode; kappa (2/3 - 4/9) / (1 - 4/9), Matthews correlation (2*3 - 4) / sqrt(4*4)
+	a.Decode = func(v float64) string { return []string{"cat", "dog"}[int(v)] }
+	report := a.Report()
+	for _, line := range []string{
+		"cat     0.5000  1.0000    0.6667        1",
+		"dog     1.0000  0.5000    0.6667        2",
+		"accuracy                       0.6667        3",
+		"macro avg     0.7500  0.7500    0.6667        3",
+		"weighted avg     0.8333  0.6667    0.6667        3",
+		"balanced accuracy                       0.7500",
+		"Cohen's kappa                       0.4000",
+		"Matthews corr.                       0.5000",
+		"actual \\ predicted  cat  dog",
+		"log-loss             0.4987",
+		"ROC-AUC              1.0000",
+		"PR-AUC               1.0000",
+	} {
+		if !strings.Contains(report, line) {
+			t.Errorf("report has no line %q:\n%s", line, report)
+		}
+	}
+
+	// outputs that are not probabilities of classes are left out
+	a.Proba = [][]float64{{0.1, 0.2, 0.7}, {0.3}, {0.5}}
+	if report := a.Report(); strings.Contains(report, "log-loss") || !strings.Contains(report, "accuracy") {
+		t.Errorf("report of outputs of different lengths is\n%s", report)
+	}
+	if report := (&Evaluation{}).Report(); strings.Contains(report, "accuracy") {
+		t.Errorf("report of no predictions is\n%s", report)
+	}
+
+}
@@ -0,0 +1,513 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+)
+
+// ARFFOptions represents settings of the ARFF loader. The zero value reads the last attribute as target.
+type ARFFOptions struct {
+
+	// Target represents the attribute of expected value: its name, or its index (negative from the end,
+	// "-1" is the last attribute). Empty is the last attribute
+	Target string
+
+}
+
+// arffAttribute represents an attribute declared in the header of an ARFF file.
+type arffAttribute struct {
+	name   string
+	kind   string
+	values []string
+	index  map[string]int
+}
+
+// kinds of ARFF attributes
+const (
+	arffNumeric = "numeric"
+	arffNominal = "nominal"
+	arffString  = "string"
+	arffDate    = "date"
+)
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+// LoadDatasetFromARFFFile loads a file in the Weka ARFF format into a Dataset, as specified by optional
+// settings (see ARFFOptions). Dense and sparse data are read, and the types declared in the header are kept:
+// nominal features are categorical, valued the index of their category (see Dataset.Categories), a nominal
+// target is a classification with classes in their declared order, a numeric one a regression. String and
+// date features are skipped. Missing values (?) are read as NaN, and rows with missing target are skipped.
+// Errors in the file are reported as *ParseError, with line and column (the attribute, from 1).
+func LoadDatasetFromARFFFile(filePath string, opts ...ARFFOptions) (*Dataset, error) {
+
+	var opt ARFFOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readARFF(f, opt)
+
+}
+
+// readARFF reads a dataset in ARFF format from r.
+func readARFF(r io.Reader, opt ARFFOptions) (*Dataset, error) {
+
+	var relation string
+	var attributes []*arffAttribute
+	var patterns []Pattern
+	var labels *LabelEncoder
+	target, data := -1, false
+
+	err := readLines(r, func(line int, s string) error {
+
+		s = strings.TrimSpace(s)
+		if s == "" || s[0] == '%' {
+			return nil
+		}
+
+		// header
+		if !data {
+			keyword, rest := s, ""
+			if i := strings.IndexAny(s, " \t"); i >= 0 {
+				keyword, rest = s[:i], strings.TrimSpace(s[i+1:])
+			}
+			switch strings.ToLower(keyword) {
+			case "@relation":
+				name, _, err := arffName(rest)
+				if err != nil {
+					return &ParseError{Line: line, Err: err}
+				}
+				relation = name
+			case "@attribute":
+				a, err := parseARFFAttribute(rest)
+				if err != nil {
+					return &ParseError{Line: line, Err: err}
+				}
+				attributes = append(attributes, a)
+			case "@data":
+				var err error
+				if target, labels, err = arffTarget(attributes, opt); err != nil {
+					return &ParseError{Line: line, Err: err}
+				}
+				data = true
+			default:
+				return &ParseError{Line: line, Err: fmt.Errorf("unknown declaration %q", keyword)}
+			}
+			return nil
+		}
+
+		// data, dense or sparse
+		values, err := arffValues(s, attributes)
+		if err != nil {
+			return &ParseError{Line: line, Err: err}
+		}
+
+		p := Pattern{}
+		for j, a := range attributes {
+
+			v := values[j]
+			if j == target {
+				if v == "?" {
+					arffWarn(&ParseError{Line: line, Column: j + 1, Name: a.name, Err: errors.New("missing expected value")}, "Row skipped.")
+					return nil
+				}
+				if a.kind == arffNumeric {
+					f, err := strconv.ParseFloat(v, 64)
+					if err != nil {
+						return &ParseError{Line: line, Column: j + 1, Name: a.name, Err: fmt.Errorf("%q is not a number", v)}
+					}
+					p.SingleExpectation = f
+					continue
+				}
+				if _, ok := a.index[v]; a.kind == arffNominal && !ok {
+					return &ParseError{Line: line, Column: j + 1, Name: a.name, Err: fmt.Errorf("%q is not a declared value", v)}
+				}
+				p.SingleRawExpectation = v
+				continue
+			}
+
+			switch a.kind {
+			case arffNumeric:
+				f := math.NaN()
+				if v != "?" {
+					if f, err = strconv.ParseFloat(v, 64); err != nil {
+						return &ParseError{Line: line, Column: j + 1, Name: a.name, Err: fmt.Errorf("%q is not a number", v)}
+					}
+				}
+				p.Features = append(p.Features, f)
+			case arffNominal:
+				f := math.NaN()
+				if v != "?" {
+					i, ok := a.index[v]
+					if !ok {
+						return &ParseError{Line: line, Column: j + 1, Name: a.name, Err: fmt.Errorf("%q is not a declared value", v)}
+					}
+					f = float64(i)
+				}
+				p.Features = append(p.Features, f)
+			}
+
+		}
+		patterns = append(patterns, p)
+		return nil
+
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !data {
+		return nil, errors.New("neural: ARFF file without @data section")
+	}
+
+	// features, skipping string and date attributes
+	var columns []string
+	var categories [][]string
+	for j, a := range attributes {
+		if j != target && (a.kind == arffNumeric || a.kind == arffNominal) {
+			columns = append(columns, a.name)
+			categories = append(categories, a.values)
+		}
+	}
+
+	d := newDataset(patterns, columns, labels)
+	d.Name, d.Target, d.Categories = relation, attributes[target].name, categories
+	for j, c := range categories {
+		if c != nil {
+			d.Types[j] = CategoricalFeature
+		}
+	}
+	if attributes[target].kind == arffNumeric {
+		d.TargetType = RegressionTarget
+	}
+
+	log.WithFields(log.Fields{
+		"level":    "info",
+		"place":    "patterns",
+		"method":   "readARFF",
+		"relation": relation,
+		"readData": len(patterns),
+	}).Info("File reading completed.")
+
+	return d, nil
+
+}
+
+// parseARFFAttribute parses the declaration of an attribute, after @attribute.
+func parseARFFAttribute(s string) (*arffAttribute, error) {
+
+	name, rest, err := arffName(s)
+	if err != nil {
+		return nil, err
+	}
+	a := &arffAttribute{name: name}
+
+	// nominal values
+	if strings.HasPrefix(rest, "{") {
+		if !strings.HasSuffix(rest, "}") {
+			return nil, fmt.Errorf("attribute %q: unterminated nominal values", name)
+		}
+		if a.values, err = arffFields(rest[1 : len(rest)-1]); err != nil {
+			return nil, fmt.Errorf("attribute %q: %v", name, err)
+		}
+		a.kind, a.index = arffNominal, map[string]int{}
+		for i, v := range a.values {
+			a.index[v] = i
+		}
+		return a, nil
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("attribute %q: missing type", name)
+	}
+	kind := strings.ToLower(fields[0])
+	switch kind {
+	case "numeric", "real", "integer":
+		a.kind = arffNumeric
+	case arffString, arffDate:
+		a.kind = kind
+	default:
+		return nil, fmt.Errorf("attribute %q: unsupported type %q", name, rest)
+	}
+	return a, nil
+
+}
+
+// arffTarget returns the index of the target attribute and the encoder of its classes (nil for numeric ones).
+func arffTarget(attributes []*arffAttribute, opt ARFFOptions) (int, *LabelEncoder, error) {
+
+	if len(attributes) < 2 {
+		return 0, nil, fmt.Errorf("%d attributes, at least one feature and the expected value are needed", len(attributes))
+	}
+
+	names := make([]string, len(attributes))
+	for j, a := range attributes {
+		names[j] = a.name
+	}
+	target := len(attributes) - 1
+	if opt.Target != "" {
+		var err error
+		if target, err = columnIndex(names, opt.Target, len(attributes)); err != nil {
+			return 0, nil, fmt.Errorf("target attribute: %v", err)
+		}
+	}
+
+	for j, a := range attributes {
+		if j != target && (a.kind == arffString || a.kind == arffDate) {
+			arffWarn(fmt.Errorf("%s attribute %q", a.kind, a.name), "Attribute skipped.")
+		}
+	}
+
+	switch a := attributes[target]; a.kind {
+	case arffNumeric:
+		return target, nil, nil
+	case arffNominal:
+		return target, NewLabelEncoder(a.values...), nil
+	case arffString:
+		return target, &LabelEncoder{}, nil
+	}
+	return 0, nil, fmt.Errorf("target attribute %q cannot be a date", attributes[target].name)
+
+}
+
+// arffValues returns the values of attributes in a data row, dense or sparse ({index value, ...}).
+func arffValues(s string, attributes []*arffAttribute) ([]string, error) {
+
+	n := len(attributes)
+
+	// dense row
+	if !strings.HasPrefix(s, "{") {
+		values, err := arffFields(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != n {
+			return nil, fmt.Errorf("%d values, %d attributes declared", len(values), n)
+		}
+		return values, nil
+	}
+
+	// sparse row: values not given are 0, or the first value of nominal attributes
+	if !strings.HasSuffix(s, "}") {
+		return nil, errors.New("unterminated sparse row")
+	}
+	values := make([]string, n)
+	for j, a := range attributes {
+		values[j] = "0"
+		if a.kind == arffNominal && len(a.values) > 0 {
+			values[j] = a.values[0]
+		}
+	}
+	pairs, err := arffFields(s[1 : len(s)-1])
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		i := strings.IndexAny(pair, " \t")
+		if i < 0 {
+			return nil, fmt.Errorf("%q is not an index value pair", pair)
+		}
+		j, err := strconv.Atoi(pair[:i])
+		if err != nil || j < 0 || j >= n {
+			return nil, fmt.Errorf("invalid attribute index %q", pair[:i])
+		}
+		v, _, err := arffName(strings.TrimSpace(pair[i+1:]))
+		if err != nil {
+			return nil, err
+		}
+		values[j] = v
+	}
+	return values, nil
+
+}
+
+// arffFields splits s into comma separated fields, removing spaces around them and quotes.
+func arffFields(s string) ([]string, error) {
+
+	var fields []string
+	if strings.TrimSpace(s) == "" {
+		return fields, nil
+	}
+	for {
+		f, rest, err := arffToken(s, ",")
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			return fields, nil
+		}
+		if rest[0] != ',' {
+			return nil, fmt.Errorf("unexpected %q after %q", rest, f)
+		}
+		s = rest[1:]
+	}
+
+}
+
+// arffName returns the name at the beginning of s, quoted or ending with a space, and the rest of s.
+func arffName(s string) (string, string, error) {
+
+	name, rest, err := arffToken(s, " \t{")
+	if err != nil {
+		return "", "", err
+	}
+	if name == "" {
+		return "", "", errors.New("missing name")
+	}
+	return name, strings.TrimSpace(rest), nil
+
+}
+
+// arffToken returns the token at the beginning of s, unquoted or ending before one of stop, and the rest of s.
+func arffToken(s string, stop string) (string, string, error) {
+
+	s = strings.TrimLeft(s, " \t")
+	if s == "" || (s[0] != '\'' && s[0] != '"') {
+		i := strings.IndexAny(s, stop)
+		if i < 0 {
+			i = len(s)
+		}
+		return strings.TrimSpace(s[:i]), s[i:], nil
+	}
+
+	// quoted, with backslash escapes
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+			}
+			b.WriteByte(s[i])
+		case s[0]:
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quote in %q", s)
+
+}
+
+// arffQuote returns s quoted if needed in ARFF files.
+func arffQuote(s string) string {
+
+	if s != "" && s != "?" && !strings.ContainsAny(s, " \t,'\"%{}\\") {
+		return s
+	}
+	r := strings.NewReplacer("\\", "\\\\", "'", "\\'")
+	return "'" + r.Replace(s) + "'"
+
+}
+
+// arffWarn logs err, found reading an ARFF file.
+func arffWarn(err error, msg string) {
+
+	log.WithFields(log.Fields{
+		"level":  "warning",
+		"place":  "patterns",
+		"method": "readARFF",
+		"error":  err,
+	}).Warn(msg)
+
+}
+
+// WriteARFF writes the dataset to w in ARFF format, with the schema in the header: categorical features with
+// names of categories and classes are nominal attributes, other features and regression targets numeric ones.
+// Missing values are written as ?. Multi-label targets are not supported.
+func (d *Dataset) WriteARFF(w io.Writer) error {
+
+	if d.TargetType == MultiLabelTarget {
+		return errors.New("neural: ARFF files cannot hold multi-label targets")
+	}
+
+	name := d.Name
+	if name == "" {
+		name = "dataset"
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "@relation %s\n\n", arffQuote(name))
+
+	// header
+	nominal := func(values []string) string {
+		q := make([]string, len(values))
+		for i, v := range values {
+			q[i] = arffQuote(v)
+		}
+		return "{" + strings.Join(q, ",") + "}"
+	}
+	categories := make([][]string, len(d.Columns))
+	for j, c := range d.Columns {
+		if j < len(d.Categories) && j < len(d.Types) && d.Types[j] == CategoricalFeature && d.Categories[j] != nil {
+			categories[j] = d.Categories[j]
+			fmt.Fprintf(bw, "@attribute %s %s\n", arffQuote(c), nominal(categories[j]))
+		} else {
+			fmt.Fprintf(bw, "@attribute %s numeric\n", arffQuote(c))
+		}
+	}
+	classes := d.Classes()
+	if classes != nil {
+		fmt.Fprintf(bw, "@attribute %s %s\n", arffQuote(d.Target), nominal(classes))
+	} else {
+		fmt.Fprintf(bw, "@attribute %s numeric\n", arffQuote(d.Target))
+	}
+
+	// data
+	bw.WriteString("\n@data\n")
+	value := func(v float64, values []string) string {
+		i := int(v)
+		switch {
+		case math.IsNaN(v):
+			return "?"
+		case values == nil:
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		case float64(i) != v || i < 0 || i >= len(values):
+			return "?"
+		}
+		return arffQuote(values[i])
+	}
+	for i := range d.Patterns {
+		p := &d.Patterns[i]
+		for j, v := range p.Features {
+			var values []string
+			if j < len(categories) {
+				values = categories[j]
+			}
+			bw.WriteString(value(v, values))
+			bw.WriteByte(',')
+		}
+		bw.WriteString(value(p.SingleExpectation, classes))
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+
+}
@@ -0,0 +1,261 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"math/rand"
+	"os"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+	// this repo internal import
+	mu "github.com/made2591/go-perceptron-go/util"
+
+)
+
+// TrainState represents the state of a training, shared with callbacks.
+type TrainState struct {
+
+	// Method represents name of trainer
+	Method string
+	// Epochs represents maximum number of epochs
+	Epochs int
+	// Epoch represents actual epoch, from 0
+	Epoch int
+	// Batch represents index of last batch in actual epoch
+	Batch int
+	// Step represents number of updates done
+	Step int
+	// BatchLoss represents mean error of patterns in last batch
+	BatchLoss float64
+	// Stats represents statistics of last epoch completed
+	Stats EpochStats
+	// History represents history of training so far
+	History *History
+
+	// Network represents network trained, nil when training a single neuron
+	Network *MultiLayerNetwork
+	// Neuron represents neuron trained, nil when training a network
+	Neuron *NeuronUnit
+	// Patterns represents training set (nil when read from a PatternSource)
+	Patterns []Pattern
+
+	// Stop can be set by callbacks to stop training after actual batch
+	Stop bool
+
+	// src represents the source of randomness of training
+	src *mu.Source
+	// data represents the reader of training patterns, whose state is saved in checkpoints
+	data epochReader
+	// snapshot adds to a checkpoint the state of the model trained
+	snapshot func(c *Checkpoint)
+
+}
+
+// Callback represents a set of hooks called by trainers during training.
+// Callbacks with a state of their own training can have a Clone() Callback method: clones of a model (see Model)
+// get copies made with it, so that they can be trained at the same time; otherwise clones share the callback.
+type Callback interface {
+
+	// OnTrainBegin is called before first epoch
+	OnTrainBegin(s *TrainState)
+	// OnEpochBegin is called at the beginning of each epoch
+	OnEpochBegin(s *TrainState)
+	// OnBatchEnd is called after weights are updated with each batch
+	OnBatchEnd(s *TrainState)
+	// OnEpochEnd is called at the end of each epoch, when statistics of epoch are available
+	OnEpochEnd(s *TrainState)
+	// OnTrainEnd is called when training ends
+	OnTrainEnd(s *TrainState)
+
+}
+
+// BaseCallback implements Callback doing nothing: embed it to implement only some hooks.
+type BaseCallback struct{}
+
+// CallbackFuncs implements Callback with optional functions, nil ones are not called.
+type CallbackFuncs struct {
+	TrainBegin func(s *TrainState)
+	EpochBegin func(s *TrainState)
+	BatchEnd   func(s *TrainState)
+	EpochEnd   func(s *TrainState)
+	TrainEnd   func(s *TrainState)
+}
+
+// LogCallback logs statistics of each epoch with logrus, at debug level.
+type LogCallback struct {
+	BaseCallback
+}
+
+// ElmanSumCallback logs the sum computed by an Elman network trained on the "learn to sum" task
+// (see CreateRandomPatternArray) for a random pattern, every Every epochs.
+type ElmanSumCallback struct {
+	BaseCallback
+
+	// Every represents number of epochs between two logs
+	Every int
+	// Rand represents the generator used to pick patterns (nil uses current time)
+	Rand *rand.Rand
+}
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+func (BaseCallback) OnTrainBegin(s *TrainState) {}
+func (BaseCallback) OnEpochBegin(s *TrainState) {}
+func (BaseCallback) OnBatchEnd(s *TrainState)   {}
+func (BaseCallback) OnEpochEnd(s *TrainState)   {}
+func (BaseCallback) OnTrainEnd(s *TrainState)   {}
+
+func (c CallbackFuncs) OnTrainBegin(s *TrainState) {
+	if c.TrainBegin != nil {
+		c.TrainBegin(s)
+	}
+}
+
+func (c CallbackFuncs) OnEpochBegin(s *TrainState) {
+	if c.EpochBegin != nil {
+		c.EpochBegin(s)
+	}
+}
+
+func (c CallbackFuncs) OnBatchEnd(s *TrainState) {
+	if c.BatchEnd != nil {
+		c.BatchEnd(s)
+	}
+}
+
+func (c CallbackFuncs) OnEpochEnd(s *TrainState) {
+	if c.EpochEnd != nil {
+		c.EpochEnd(s)
+	}
+}
+
+func (c CallbackFuncs) OnTrainEnd(s *TrainState) {
+	if c.TrainEnd != nil {
+		c.TrainEnd(s)
+	}
+}
+
+func (LogCallback) OnEpochEnd(s *TrainState) {
+
+	log.WithFields(log.Fields{
+		"level":              "debug",
+		"place":              "training",
+		"method":             s.Method,
+		"epoch":              s.Epoch,
+		"learningRate":       s.Stats.LearningRate,
+		"trainLoss":          s.Stats.TrainLoss,
+		"trainAccuracy":      s.Stats.TrainAccuracy,
+		"validationLoss":     s.Stats.ValidationLoss,
+		"validationAccuracy": s.Stats.ValidationAccuracy,
+	}).Debug("Training epoch completed.")
+
+}
+
+// Clone returns a copy of c with its own generator, seeded from c.Rand if given.
+func (c ElmanSumCallback) Clone() Callback {
+
+	if c.Rand != nil {
+		c.Rand = rand.New(rand.NewSource(c.Rand.Int63()))
+	}
+	return c
+
+}
+
+func (c ElmanSumCallback) OnEpochEnd(s *TrainState) {
+
+	if s.Network == nil || len(s.Patterns) == 0 || c.Every < 1 || s.Epoch % c.Every != 0 {
+		return
+	}
+
+	pattern := s.Patterns[mu.Random(0, len(s.Patterns)-1, c.Rand)]
+
+	// get output from network
+	o_out := Execute(s.Network, &pattern, 1)
+	for o_out_i, o_out_v := range(o_out) {
+		o_out[o_out_i] = mu.Round(o_out_v, .5, 0)
+	}
+	log.WithFields(log.Fields{
+		"SUM":	"  ==========================",
+	}).Info()
+	log.WithFields(log.Fields{
+		"a_n_1":	mu.ConvertBinToInt(pattern.Features[0:int(len(pattern.Features)/2)]),
+		"a_n_2":	pattern.Features[0:int(len(pattern.Features)/2)],
+	}).Info()
+	log.WithFields(log.Fields{
+		"b_n_1":	mu.ConvertBinToInt(pattern.Features[int(len(pattern.Features)/2):]),
+		"b_n_2":	pattern.Features[int(len(pattern.Features)/2):],
+	}).Info()
+	log.WithFields(log.Fields{
+		"sum_1":	mu.ConvertBinToInt(pattern.MultipleExpectation),
+		"sum_2":	pattern.MultipleExpectation,
+	}).Info()
+	log.WithFields(log.Fields{
+		"sum_1":	mu.ConvertBinToInt(o_out),
+		"sum_2":	o_out,
+	}).Info()
+	log.WithFields(log.Fields{
+		"END":	"  ==========================",
+	}).Info()
+
+}
+
+// cloneCallbacks returns callbacks for a clone of a model: callbacks with a Clone() Callback method are copied
+// with it, the others are shared. Nil stays nil, for the default callbacks.
+func cloneCallbacks(cs []Callback) []Callback {
+
+	if cs == nil {
+		return nil
+	}
+	c := make([]Callback, len(cs))
+	for i, cb := range cs {
+		if cl, ok := cb.(interface{ Clone() Callback }); ok {
+			cb = cl.Clone()
+		}
+		c[i] = cb
+	}
+	return c
+
+}
+
+// callbacks represents the callbacks of a training, called in order.
+type callbacks []Callback
+
+func (cs callbacks) trainBegin(s *TrainState) {
+	for _, c := range cs {
+		c.OnTrainBegin(s)
+	}
+}
+
+func (cs callbacks) epochBegin(s *TrainState) {
+	for _, c := range cs {
+		c.OnEpochBegin(s)
+	}
+}
+
+func (cs callbacks) batchEnd(s *TrainState) {
+	for _, c := range cs {
+		c.OnBatchEnd(s)
+	}
+}
+
+func (cs callbacks) epochEnd(s *TrainState) {
+	for _, c := range cs {
+		c.OnEpochEnd(s)
+	}
+}
+
+func (cs callbacks) trainEnd(s *TrainState) {
+	for _, c := range cs {
+		c.OnTrainEnd(s)
+	}
+}
@@ -0,0 +1,333 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+)
+
+// Checkpoint represents the state of a training at the end of an epoch: model trained (with optimizer state),
+// epoch, state of randomness and history. Training can be resumed from it with TrainOptions.Resume,
+// continuing exactly as if it was never stopped. As checkpoints are only taken at the end of an epoch,
+// a training interrupted in the middle of an epoch resumes from its beginning, doing its updates again.
+type Checkpoint struct {
+
+	// Method represents name of trainer
+	Method string
+	// Epoch represents number of epochs completed
+	Epoch int
+	// Step represents number of updates done
+	Step int
+	// Rand represents state of the source of randomness of training
+	Rand uint64
+	// Source represents state of the PatternSource of training patterns, if any (see RestorableSource)
+	Source []uint64
+	// History represents history of training so far
+	History History
+
+	// network and neuron represent the model trained, best its weights in the best epoch (if kept for early stopping)
+	network    *mlpSnapshot
+	neuron     *neuronSnapshot
+	best       *networkWeights
+	bestNeuron *neuronSnapshot
+
+}
+
+// checkpointSnapshot represents the persistent state of a Checkpoint.
+type checkpointSnapshot struct {
+	Method     string          `json:"method"`
+	Epoch      int             `json:"epoch"`
+	Step       int             `json:"step"`
+	Rand       uint64          `json:"rand"`
+	Source     []uint64        `json:"source,omitempty"`
+	History    History         `json:"history"`
+	Network    *mlpSnapshot    `json:"network,omitempty"`
+	Neuron     *neuronSnapshot `json:"neuron,omitempty"`
+	Best       *networkWeights `json:"best,omitempty"`
+	BestNeuron *neuronSnapshot `json:"best_neuron,omitempty"`
+}
+
+// Checkpointer is a Callback that writes a checkpoint of training in a directory every Every epochs
+// and / or every Interval. Files are written atomically and named by epoch (checkpoint-00000042.json):
+// use LatestCheckpoint and LoadCheckpoint to resume. Use it as a pointer, it keeps the time of last checkpoint.
+// Clones of a model (e.g. folds of cross validation) write their checkpoints in subdirectories of Dir, see Clone.
+type Checkpointer struct {
+	BaseCallback
+
+	// Dir represents directory where checkpoints are written, created if missing
+	Dir string
+	// Every represents number of epochs between two checkpoints (0 uses only Interval)
+	Every int
+	// Interval represents minimum time between two checkpoints (0 uses only Every)
+	Interval time.Duration
+	// Keep represents number of most recent checkpoints kept in Dir (0 keeps all)
+	Keep int
+
+	// Err represents last error writing a checkpoint, also logged: training goes on anyway
+	Err error
+
+	// last represents time of last checkpoint
+	last time.Time
+	// clones represents number of clones made
+	clones int32
+}
+
+const (
+
+	// checkpointPattern is the name of checkpoint files written by Checkpointer, by number of epochs completed
+	checkpointPattern = "checkpoint-%08d.json"
+
+)
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+// Checkpoint returns the state of training, to resume it later with TrainOptions.Resume.
+// The state is exact only at the end of an epoch, so call it in OnEpochEnd.
+func (s *TrainState) Checkpoint() *Checkpoint {
+
+	c := &Checkpoint{Method: s.Method, Epoch: len(s.History.Epochs), Step: s.Step, History: *s.History}
+	c.History.Epochs = append([]EpochStats(nil), s.History.Epochs...)
+	if s.src != nil {
+		c.Rand = s.src.State()
+	}
+	if s.data != nil {
+		// a source that cannot be restored fails on resume
+		c.Source, _ = s.data.state()
+	}
+	if s.snapshot != nil {
+		s.snapshot(c)
+	}
+	return c
+
+}
+
+// resume restores in s the state of training saved in c. The monitored loss of each epoch is passed again
+// to m and to schedule sc, so that early stopping and schedules go on as if training was never stopped.
+// It returns the first epoch to train, or an error if the source of training patterns cannot be restored.
+func (c *Checkpoint) resume(s *TrainState, m *monitor, sc Schedule) (int, error) {
+
+	s.src.SetState(c.Rand)
+	s.Step = c.Step
+	if s.data != nil {
+		if err := s.data.restore(c.Source); err != nil {
+			return 0, fmt.Errorf("neural: cannot resume %s: %v", c.Method, err)
+		}
+	}
+
+	h := s.History
+	for _, e := range c.History.Epochs {
+		h.Epochs = append(h.Epochs, e)
+		observeMetric(sc, e.monitored())
+		m.observe(h, e.Epoch, e.monitored())
+	}
+	h.Stopped, s.Stop = c.History.Stopped, c.History.Stopped
+	if len(h.Epochs) > 0 {
+		s.Stats = h.Epochs[len(h.Epochs)-1]
+	}
+
+	return c.Epoch, nil
+
+}
+
+// Save writes the checkpoint to w using the versioned JSON format.
+func (c *Checkpoint) Save(w io.Writer) error {
+
+	return writeModel(w, kindCheckpoint, false, &checkpointSnapshot{
+		Method: c.Method, Epoch: c.Epoch, Step: c.Step, Rand: c.Rand, Source: c.Source, History: c.History,
+		Network: c.network, Neuron: c.neuron, Best: c.best, BestNeuron: c.bestNeuron,
+	})
+
+}
+
+// ReadCheckpoint reads a checkpoint written by Checkpoint.Save from r.
+func ReadCheckpoint(r io.Reader) (*Checkpoint, error) {
+
+	var s checkpointSnapshot
+	if err := readModel(r, kindCheckpoint, &s); err != nil {
+		return nil, err
+	}
+	if s.Network == nil && s.Neuron == nil {
+		return nil, fmt.Errorf("neural: checkpoint contains no model")
+	}
+
+	return &Checkpoint{
+		Method: s.Method, Epoch: s.Epoch, Step: s.Step, Rand: s.Rand, Source: s.Source, History: s.History,
+		network: s.Network, neuron: s.Neuron, best: s.Best, bestNeuron: s.BestNeuron,
+	}, nil
+
+}
+
+// LoadCheckpoint reads the checkpoint file at path.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadCheckpoint(f)
+
+}
+
+// Checkpoints returns paths of checkpoint files written by a Checkpointer in dir, from the oldest epoch to the latest.
+func Checkpoints(dir string) ([]string, error) {
+
+	paths, err := filepath.Glob(filepath.Join(dir, "checkpoint-*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	// keep only well named files, by epoch
+	epochs := map[string]int{}
+	var cs []string
+	for _, p := range paths {
+		var e int
+		if _, err := fmt.Sscanf(filepath.Base(p), checkpointPattern, &e); err == nil {
+			epochs[p] = e
+			cs = append(cs, p)
+		}
+	}
+	sort.Slice(cs, func(i, j int) bool { return epochs[cs[i]] < epochs[cs[j]] })
+
+	return cs, nil
+
+}
+
+// LatestCheckpoint returns path of the checkpoint file of the latest epoch in dir, empty if there is none.
+func LatestCheckpoint(dir string) (string, error) {
+
+	cs, err := Checkpoints(dir)
+	if err != nil || len(cs) == 0 {
+		return "", err
+	}
+	return cs[len(cs)-1], nil
+
+}
+
+// Clone returns a Checkpointer with the same settings writing in its own subdirectory of Dir, named by number
+// of clone (clone-1, clone-2...): clones of a model are made once for each fold, in order.
+func (cp *Checkpointer) Clone() Callback {
+
+	n := atomic.AddInt32(&cp.clones, 1)
+	return &Checkpointer{
+		Dir: filepath.Join(cp.Dir, fmt.Sprintf("clone-%d", n)), Every: cp.Every, Interval: cp.Interval, Keep: cp.Keep,
+	}
+
+}
+
+func (cp *Checkpointer) OnTrainBegin(s *TrainState) {
+	cp.last = time.Now()
+}
+
+func (cp *Checkpointer) OnEpochEnd(s *TrainState) {
+
+	byEpoch := cp.Every > 0 && (s.Epoch+1)%cp.Every == 0
+	byTime := cp.Interval > 0 && time.Since(cp.last) >= cp.Interval
+	if !byEpoch && !byTime {
+		return
+	}
+
+	c := s.Checkpoint()
+	path, err := cp.write(c)
+	if err == nil {
+		err = cp.prune()
+	}
+	cp.last = time.Now()
+
+	if err != nil {
+		cp.Err = err
+		log.WithFields(log.Fields{
+			"level":  "warning",
+			"place":  "checkpoint",
+			"method": s.Method,
+			"epoch":  s.Epoch,
+			"error":  err,
+		}).Warn("Checkpoint failed.")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"level":  "debug",
+		"place":  "checkpoint",
+		"method": s.Method,
+		"epoch":  s.Epoch,
+		"path":   path,
+	}).Debug("Checkpoint written.")
+
+}
+
+// write writes c in Dir through a temporary file renamed when complete, so that a checkpoint is never partial.
+// It returns the path of the checkpoint.
+func (cp *Checkpointer) write(c *Checkpoint) (string, error) {
+
+	if err := os.MkdirAll(cp.Dir, 0755); err != nil {
+		return "", err
+	}
+
+	f, err := ioutil.TempFile(cp.Dir, ".checkpoint-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	tmp := f.Name()
+
+	err = c.Save(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	path := filepath.Join(cp.Dir, fmt.Sprintf(checkpointPattern, c.Epoch))
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return path, nil
+
+}
+
+// prune removes the oldest checkpoints in Dir, keeping the most recent Keep.
+func (cp *Checkpointer) prune() error {
+
+	if cp.Keep < 1 {
+		return nil
+	}
+
+	cs, err := Checkpoints(cp.Dir)
+	if err != nil {
+		return err
+	}
+	for len(cs) > cp.Keep {
+		if err := os.Remove(cs[0]); err != nil {
+			return err
+		}
+		cs = cs[1:]
+	}
+	return nil
+
+}
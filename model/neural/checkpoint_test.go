@@ -0,0 +1,128 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sync"
+	"testing"
+
+)
+
+// plainSource is a source of patterns that does not implement RestorableSource.
+type plainSource struct {
+	PatternSource
+}
+
+// #######################################################################################
+
+// sourcePatterns returns patterns of two classes, sorted by class.
+func sourcePatterns() []Pattern {
+
+	patterns := make([]Pattern, 40)
+	for i := range patterns {
+		c := float64(i / 20)
+		patterns[i] = Pattern{Features: []float64{c + 0.1*float64(i%7), 1 - c + 0.05*float64(i%5)}, SingleExpectation: c}
+	}
+	return patterns
+
+}
+
+// trainShuffled trains a network for epochs epochs on a shuffled source, with a checkpoint at the end of each epoch.
+// It returns the network and its checkpoints.
+func trainShuffled(src PatternSource, epochs int, resume *Checkpoint) (*MultiLayerNetwork, []*Checkpoint, error) {
+
+	mlp := PrepareMLPNet([]int{2, 4, 2}, 0.3, Sigmoid, rand.New(rand.NewSource(3)))
+	var cs []*Checkpoint
+	save := CallbackFuncs{EpochEnd: func(s *TrainState) { cs = append(cs, s.Checkpoint()) }}
+	_, err := MLPTrainSourceContext(context.Background(), &mlp, src, []string{"0", "1"}, epochs, TrainOptions{Callbacks: []Callback{save}, Resume: resume})
+	return &mlp, cs, err
+
+}
+
+// TestResumeShuffledSource checks that training from a Shuffled source resumed from a checkpoint, saved and read
+// back, reaches the same weights as the training never stopped.
+func TestResumeShuffledSource(t *testing.T) {
+
+	shuffled := func(seed int64) PatternSource {
+		return Shuffled(SliceSource(sourcePatterns()), 8, rand.New(rand.NewSource(seed)))
+	}
+
+	whole, cs, err := trainShuffled(shuffled(1), 6, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := cs[2].Save(&b); err != nil {
+		t.Fatal(err)
+	}
+	c, err := ReadCheckpoint(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the generator of the new source is seeded differently: its state comes from the checkpoint
+	resumed, _, err := trainShuffled(shuffled(2), 6, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 1; k < len(whole.NeuralLayers); k++ {
+		for i, w := range whole.NeuralLayers[k].W {
+			if resumed.NeuralLayers[k].W[i] != w {
+				t.Fatalf("weight %d of layer %d is %v after resume, %v without stopping", i, k, resumed.NeuralLayers[k].W[i], w)
+			}
+		}
+	}
+
+	// a source whose state is unknown cannot be resumed
+	if _, _, err := trainShuffled(plainSource{SliceSource(sourcePatterns())}, 6, c); err == nil {
+		t.Errorf("resume from a source that cannot be restored succeeded")
+	}
+
+}
+
+// TestCheckpointerClones checks that clones of a model trained at the same time write checkpoints in their own
+// directories, each one resuming its own training.
+func TestCheckpointerClones(t *testing.T) {
+
+	dir := t.TempDir()
+	mlp := PrepareMLPNet([]int{2, 4, 2}, 0.3, Sigmoid, rand.New(rand.NewSource(3)))
+	cp := &Checkpointer{Dir: dir, Every: 1, Keep: 2}
+	model := MLPModel(&mlp, []string{"0", "1"}, 4, TrainOptions{Callbacks: []Callback{cp}})
+
+	const clones = 3
+	var wg sync.WaitGroup
+	for i := 0; i < clones; i++ {
+		wg.Add(1)
+		go func(m Model) {
+			defer wg.Done()
+			m.Fit(context.Background(), sourcePatterns())
+		}(model.Clone())
+	}
+	wg.Wait()
+
+	if cs, _ := Checkpoints(dir); len(cs) != 0 {
+		t.Errorf("clones wrote %d checkpoints in the directory of the model", len(cs))
+	}
+	for i := 1; i <= clones; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("clone-%d", i))
+		cs, err := Checkpoints(sub)
+		if err != nil || len(cs) != 2 {
+			t.Fatalf("clone %d kept checkpoints %v (error %v), want the last 2", i, cs, err)
+		}
+		c, err := LoadCheckpoint(cs[1])
+		if err != nil || c.Epoch != 4 {
+			t.Errorf("last checkpoint of clone %d is %v (error %v), want epoch 4", i, cs[1], err)
+		}
+	}
+	if cp.Err != nil {
+		t.Error(cp.Err)
+	}
+
+}
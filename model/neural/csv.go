@@ -0,0 +1,403 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+)
+
+// CSVOptions represents settings of CSV loaders. The zero value reads files without header, with comma
+// delimiter and class name in the last column.
+type CSVOptions struct {
+
+	// Header represents if the first row holds names of columns
+	Header bool
+	// Delimiter represents the field delimiter (0 is comma)
+	Delimiter rune
+	// Comment represents the character starting comment lines (0 is none)
+	Comment rune
+	// LazyQuotes represents if quotes may appear in unquoted fields and non doubled in quoted ones
+	LazyQuotes bool
+	// TrimSpace represents if leading spaces of fields are ignored
+	TrimSpace bool
+
+	// Target represents the column of expected value: its name, or its index (negative from the end,
+	// "-1" is the last column). Empty is the last column
+	Target string
+	// Regression represents if the target column holds real values to predict, read in SingleExpectation as numbers
+	// instead of class names: values that are not numbers are missing expected values (see Strict)
+	Regression bool
+	// Ignore represents columns skipped, by name or index as Target
+	Ignore []string
+	// Categorical represents columns of categories, by name or index as Target: their values are read as the index
+	// of their category, in order of appearance (see Dataset.Categories)
+	Categorical []string
+	// NA represents tokens of missing values, read as NaN (nil is "", "NA", "N/A" and "?")
+	NA []string
+	// Strict represents if values that are neither numbers nor NA tokens, and missing expected values,
+	// are errors. Otherwise they are read as NaN, and rows without expected value are skipped, with a warning
+	Strict bool
+
+	// Labels represents the encoder of class names into SingleExpectation: nil uses a new one.
+	// Classes not in the encoder are added in order of appearance
+	Labels *LabelEncoder
+
+}
+
+// ParseError represents an error reading a data file, at a line and column.
+type ParseError struct {
+
+	// Line represents line of the file, from 1
+	Line int
+	// Column represents column of the record, from 1 (0 if the error is about the whole line)
+	Column int
+	// Name represents name of the column, if known
+	Name string
+	// Err represents the error found
+	Err error
+
+}
+
+// csvReader reads patterns from CSV records, as specified by CSVOptions.
+type csvReader struct {
+	opt     CSVOptions
+	r       *csv.Reader
+	columns []string
+	target  int
+	width   int
+	ignore  map[int]bool
+	encoded map[int]*LabelEncoder
+	na      map[string]bool
+	labels  *LabelEncoder
+}
+
+// defaultNA represents tokens of missing values used if none is given
+var defaultNA = []string{"", "NA", "N/A", "?"}
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+func (e *ParseError) Error() string {
+
+	switch {
+	case e.Column == 0:
+		return fmt.Sprintf("neural: line %d: %v", e.Line, e.Err)
+	case e.Name != "":
+		return fmt.Sprintf("neural: line %d, column %d (%s): %v", e.Line, e.Column, e.Name, e.Err)
+	}
+	return fmt.Sprintf("neural: line %d, column %d: %v", e.Line, e.Column, e.Err)
+
+}
+
+// Unwrap returns the error found.
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// newCSVReader returns a reader of patterns from r, reading the header if any.
+func newCSVReader(r io.Reader, opt CSVOptions) (*csvReader, error) {
+
+	c := &csvReader{opt: opt, r: csv.NewReader(r), target: -1, labels: opt.Labels}
+	if opt.Delimiter != 0 {
+		c.r.Comma = opt.Delimiter
+	}
+	c.r.Comment = opt.Comment
+	c.r.LazyQuotes = opt.LazyQuotes
+	c.r.TrimLeadingSpace = opt.TrimSpace
+	c.r.ReuseRecord = true
+	if c.labels == nil {
+		c.labels = &LabelEncoder{}
+	}
+
+	na := opt.NA
+	if na == nil {
+		na = defaultNA
+	}
+	c.na = map[string]bool{}
+	for _, t := range na {
+		c.na[t] = true
+	}
+
+	if opt.Header {
+		h, err := c.r.Read()
+		if err == io.EOF {
+			return nil, &ParseError{Line: 1, Err: errors.New("missing header")}
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		c.columns = append([]string(nil), h...)
+		if err := c.resolve(len(h), 1); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+
+}
+
+// resolve finds indexes of target and ignored columns, in records of n fields.
+func (c *csvReader) resolve(n int, line int) error {
+
+	if n < 2 {
+		return &ParseError{Line: line, Err: fmt.Errorf("%d columns, at least one feature and the expected value are needed", n)}
+	}
+
+	var err error
+	c.target = n - 1
+	if c.opt.Target != "" {
+		if c.target, err = c.column(c.opt.Target, n); err != nil {
+			return &ParseError{Line: line, Err: fmt.Errorf("target column: %v", err)}
+		}
+	}
+
+	c.ignore = map[int]bool{}
+	for _, s := range c.opt.Ignore {
+		j, err := c.column(s, n)
+		if err != nil {
+			return &ParseError{Line: line, Err: fmt.Errorf("ignored column: %v", err)}
+		}
+		c.ignore[j] = true
+	}
+	if c.ignore[c.target] {
+		return &ParseError{Line: line, Err: fmt.Errorf("target column %q is ignored", c.name(c.target))}
+	}
+
+	c.encoded = map[int]*LabelEncoder{}
+	for _, s := range c.opt.Categorical {
+		j, err := c.column(s, n)
+		if err != nil {
+			return &ParseError{Line: line, Err: fmt.Errorf("categorical column: %v", err)}
+		}
+		c.encoded[j] = &LabelEncoder{}
+	}
+	c.width = n
+
+	return nil
+
+}
+
+// column returns the index of column s, a name in the header or an index (negative from the end), in records of n fields.
+func (c *csvReader) column(s string, n int) (int, error) {
+	return columnIndex(c.columns, s, n)
+}
+
+// columnIndex returns the index of column s, one of names or an index (negative from the end), among n columns.
+func columnIndex(names []string, s string, n int) (int, error) {
+
+	for j, name := range names {
+		if name == s {
+			return j, nil
+		}
+	}
+
+	j, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("no column named %q", s)
+	}
+	if j < 0 {
+		j += n
+	}
+	if j < 0 || j >= n {
+		return 0, fmt.Errorf("index %s out of %d columns", s, n)
+	}
+	return j, nil
+
+}
+
+// name returns the name of column j, empty without header.
+func (c *csvReader) name(j int) string {
+
+	if j < len(c.columns) {
+		return c.columns[j]
+	}
+	return ""
+
+}
+
+// features returns names of feature columns, nil without header.
+func (c *csvReader) features() []string {
+
+	var names []string
+	for j := 0; j < len(c.columns); j++ {
+		if j != c.target && !c.ignore[j] {
+			names = append(names, c.columns[j])
+		}
+	}
+	return names
+
+}
+
+// categories returns names of categories of feature columns, nil for numeric ones.
+func (c *csvReader) categories() [][]string {
+
+	var categories [][]string
+	for j := 0; j < c.width; j++ {
+		if j != c.target && !c.ignore[j] {
+			var names []string
+			if e := c.encoded[j]; e != nil {
+				names = append([]string{}, e.Classes...)
+			}
+			categories = append(categories, names)
+		}
+	}
+	return categories
+
+}
+
+// read returns the next pattern, io.EOF at the end of the file.
+func (c *csvReader) read() (Pattern, error) {
+
+	for {
+
+		record, err := c.r.Read()
+		if err != nil {
+			return Pattern{}, csvError(err)
+		}
+		line, _ := c.r.FieldPos(0)
+		if c.target < 0 {
+			if err := c.resolve(len(record), line); err != nil {
+				return Pattern{}, err
+			}
+		}
+
+		p := Pattern{Features: make([]float64, 0, len(record)-1-len(c.ignore))}
+		for j, v := range record {
+			if j == c.target || c.ignore[j] {
+				continue
+			}
+			f, err := c.value(v, line, j)
+			if err != nil {
+				return Pattern{}, err
+			}
+			p.Features = append(p.Features, f)
+		}
+
+		// expected value
+		raw := record[c.target]
+		var perr *ParseError
+		if c.na[strings.TrimSpace(raw)] {
+			perr = &ParseError{Line: line, Column: c.target + 1, Name: c.name(c.target), Err: errors.New("missing expected value")}
+		} else if c.opt.Regression {
+			if p.SingleExpectation, err = strconv.ParseFloat(strings.TrimSpace(raw), 64); err != nil {
+				perr = &ParseError{Line: line, Column: c.target + 1, Name: c.name(c.target), Err: fmt.Errorf("expected value %q is not a number", raw)}
+			}
+		}
+		if perr != nil {
+			if c.opt.Strict {
+				return Pattern{}, perr
+			}
+			c.warn(perr, "Row skipped.")
+			continue
+		}
+		if c.opt.Regression {
+			return p, nil
+		}
+		c.labels.Fit(raw)
+		p.SingleRawExpectation = raw
+		p.SingleExpectation, _ = c.labels.Encode(raw)
+
+		return p, nil
+
+	}
+
+}
+
+// value parses v, in column j at line, as a feature value.
+func (c *csvReader) value(v string, line int, j int) (float64, error) {
+
+	if c.na[strings.TrimSpace(v)] {
+		return math.NaN(), nil
+	}
+	if e := c.encoded[j]; e != nil {
+		e.Fit(v)
+		return e.Encode(v)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err == nil {
+		return f, nil
+	}
+
+	perr := &ParseError{Line: line, Column: j + 1, Name: c.name(j), Err: fmt.Errorf("%q is not a number", v)}
+	if c.opt.Strict {
+		return 0, perr
+	}
+	c.warn(perr, "Value read as missing.")
+	return math.NaN(), nil
+
+}
+
+// warn logs err, found reading in non strict mode.
+func (c *csvReader) warn(err error, msg string) {
+
+	log.WithFields(log.Fields{
+		"level":  "warning",
+		"place":  "patterns",
+		"method": "readCSV",
+		"error":  err,
+	}).Warn(msg)
+
+}
+
+// csvError converts errors of encoding/csv to ParseError, leaving io.EOF unchanged.
+func csvError(err error) error {
+
+	var perr *csv.ParseError
+	if errors.As(err, &perr) {
+		if perr.Err == csv.ErrFieldCount {
+			return &ParseError{Line: perr.Line, Err: perr.Err}
+		}
+		return &ParseError{Line: perr.Line, Err: fmt.Errorf("%v, at character %d", perr.Err, perr.Column)}
+	}
+	return err
+
+}
+
+// readCSVPatterns reads all patterns from CSV content r.
+// It returns the patterns and the reader, with names of columns and class names.
+func readCSVPatterns(r io.Reader, opt CSVOptions) ([]Pattern, *csvReader, error) {
+
+	c, err := newCSVReader(r, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var patterns []Pattern
+	for {
+		p, err := c.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return patterns, c, err
+		}
+		patterns = append(patterns, p)
+	}
+
+	log.WithFields(log.Fields{
+		"level":    "info",
+		"place":    "patterns",
+		"method":   "readCSV",
+		"readData": len(patterns),
+		"msg":      "File reading completed.",
+	}).Info("File reading completed.")
+
+	return patterns, c, nil
+
+}
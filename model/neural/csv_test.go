@@ -0,0 +1,56 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+
+)
+
+// #######################################################################################
+
+// TestCSVRegressionTarget checks that a numeric target is read as a real value, and that missing values are
+// recognized with spaces around them.
+func TestCSVRegressionTarget(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "prices.csv")
+	content := "rooms,area,price\n3,80.5,120.25\n2, NA ,99\n4,120, ?\n1,40,55.5\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := LoadDatasetFromCSVFile(path, CSVOptions{Header: true, Regression: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.TargetType != RegressionTarget || d.Target != "price" {
+		t.Errorf("target %q is %v, want regression", d.Target, d.TargetType)
+	}
+
+	// the row with a missing price is skipped
+	want := []float64{120.25, 99, 55.5}
+	if len(d.Patterns) != len(want) {
+		t.Fatalf("read %d patterns, want %d", len(d.Patterns), len(want))
+	}
+	for i, p := range d.Patterns {
+		if p.SingleExpectation != want[i] || p.SingleRawExpectation != "" {
+			t.Errorf("pattern %d expects %v (%q), want %v", i, p.SingleExpectation, p.SingleRawExpectation, want[i])
+		}
+	}
+	if !math.IsNaN(d.Patterns[1].Features[1]) {
+		t.Errorf("area \" NA \" is read as %v, want NaN", d.Patterns[1].Features[1])
+	}
+
+	// in strict mode a price that is not a number is an error
+	if err := ioutil.WriteFile(path, []byte("rooms,price\n3,cheap\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDatasetFromCSVFile(path, CSVOptions{Header: true, Regression: true, Strict: true}); err == nil {
+		t.Error("price \"cheap\" is read without error in strict mode")
+	}
+
+}
@@ -0,0 +1,408 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+)
+
+// FeatureType represents the kind of values of a feature.
+type FeatureType int
+
+// TargetType represents the kind of task described by expected values of a dataset.
+type TargetType int
+
+// Dataset represents patterns together with their schema: names and types of features, kind of target
+// and encoding of classes, so that predictions can be decoded back to class names.
+type Dataset struct {
+
+	// Name represents name of the dataset (the relation of ARFF files), if any
+	Name string
+	// Patterns represents the patterns of the dataset
+	Patterns []Pattern
+	// Columns represents names of features, in order
+	Columns []string
+	// Types represents types of features, in order
+	Types []FeatureType
+	// Categories represents names of the categories of each categorical feature, whose values are their indexes
+	// (nil for other features, or if names are not known)
+	Categories [][]string
+	// Target represents name of expected value
+	Target string
+	// TargetType represents the kind of task
+	TargetType TargetType
+	// Labels represents the mapping of class names to SingleExpectation (nil without class names),
+	// or the names of outputs in MultipleExpectation of a multi-label target
+	Labels *LabelEncoder
+
+}
+
+// LabelEncoder maps class names to indexes (SingleExpectation of patterns) and back.
+// It is saved with Save and read with ReadLabelEncoder, to decode predictions of a model trained earlier.
+type LabelEncoder struct {
+
+	// Classes represents class names, by index
+	Classes []string
+
+	// index represents index of each class name
+	index map[string]int
+
+}
+
+// types of features
+const (
+
+	// NumericFeature is a real valued feature
+	NumericFeature FeatureType = iota
+	// BinaryFeature is a feature valued 0 or 1
+	BinaryFeature
+	// CategoricalFeature is a feature whose values are indexes of categories
+	CategoricalFeature
+
+)
+
+// types of target
+const (
+
+	// BinaryTarget is a classification in two classes, in SingleExpectation
+	BinaryTarget TargetType = iota
+	// MulticlassTarget is a classification in more than two classes, in SingleExpectation
+	MulticlassTarget
+	// MultiLabelTarget is a set of binary outputs, in MultipleExpectation
+	MultiLabelTarget
+	// RegressionTarget is a real value, in SingleExpectation
+	RegressionTarget
+
+)
+
+// kindLabels is the kind of a serialized LabelEncoder
+const kindLabels = "labels"
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+func (t FeatureType) String() string {
+
+	switch t {
+	case NumericFeature:
+		return "numeric"
+	case BinaryFeature:
+		return "binary"
+	case CategoricalFeature:
+		return "categorical"
+	}
+	return fmt.Sprintf("FeatureType(%d)", int(t))
+
+}
+
+func (t TargetType) String() string {
+
+	switch t {
+	case BinaryTarget:
+		return "binary"
+	case MulticlassTarget:
+		return "multiclass"
+	case MultiLabelTarget:
+		return "multi-label"
+	case RegressionTarget:
+		return "regression"
+	}
+	return fmt.Sprintf("TargetType(%d)", int(t))
+
+}
+
+// NewLabelEncoder returns an encoder of the given class names, indexed in order.
+func NewLabelEncoder(classes ...string) *LabelEncoder {
+
+	e := &LabelEncoder{}
+	e.Fit(classes...)
+	return e
+
+}
+
+// Fit adds to the encoder class names not seen yet, in order of appearance.
+func (e *LabelEncoder) Fit(labels ...string) {
+
+	if e.index == nil {
+		e.reindex()
+	}
+	for _, l := range labels {
+		if _, ok := e.index[l]; !ok {
+			e.index[l] = len(e.Classes)
+			e.Classes = append(e.Classes, l)
+		}
+	}
+
+}
+
+// Len returns the number of classes.
+func (e *LabelEncoder) Len() int {
+	return len(e.Classes)
+}
+
+// Encode returns the index of class name label, or an error if it is unknown.
+func (e *LabelEncoder) Encode(label string) (float64, error) {
+
+	if e.index != nil {
+		if i, ok := e.index[label]; ok {
+			return float64(i), nil
+		}
+	} else {
+		for i, c := range e.Classes {
+			if c == label {
+				return float64(i), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("neural: unknown class %q", label)
+
+}
+
+// Decode returns the class name of index class, or an error if it is not the index of a class.
+func (e *LabelEncoder) Decode(class float64) (string, error) {
+
+	i := int(class)
+	if float64(i) != class || i < 0 || i >= len(e.Classes) {
+		return "", fmt.Errorf("neural: %v is not the index of a class (%d classes)", class, len(e.Classes))
+	}
+	return e.Classes[i], nil
+
+}
+
+// reindex builds the index of class names.
+func (e *LabelEncoder) reindex() {
+
+	e.index = make(map[string]int, len(e.Classes))
+	for i, c := range e.Classes {
+		e.index[c] = i
+	}
+
+}
+
+// MarshalJSON encodes the encoder as the list of its class names.
+func (e *LabelEncoder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Classes)
+}
+
+// UnmarshalJSON decodes an encoder written by MarshalJSON.
+func (e *LabelEncoder) UnmarshalJSON(b []byte) error {
+
+	if err := json.Unmarshal(b, &e.Classes); err != nil {
+		return err
+	}
+	e.reindex()
+	return nil
+
+}
+
+// Save writes the encoder to w using the versioned JSON format.
+func (e *LabelEncoder) Save(w io.Writer) error {
+	return writeModel(w, kindLabels, false, e)
+}
+
+// ReadLabelEncoder reads an encoder written by LabelEncoder.Save from r.
+func ReadLabelEncoder(r io.Reader) (*LabelEncoder, error) {
+
+	e := &LabelEncoder{}
+	if err := readModel(r, kindLabels, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+
+}
+
+// NewDataset returns a dataset of patterns, inferring its schema: features named columns (x0, x1, ... if nil),
+// binary if all their values are 0 or 1 and numeric otherwise; target named y, multi-label if patterns have
+// MultipleExpectation, otherwise a classification if they have SingleRawExpectation (encoded in SingleExpectation
+// in order of appearance) or integer SingleExpectation, a regression else. Patterns given are not changed:
+// when class names are encoded, the dataset holds a copy of them.
+func NewDataset(patterns []Pattern, columns []string) *Dataset {
+	return newDataset(patterns, columns, nil)
+}
+
+// newDataset is like NewDataset, encoding class names with labels (nil uses a new encoder).
+func newDataset(patterns []Pattern, columns []string, labels *LabelEncoder) *Dataset {
+
+	d := &Dataset{Patterns: patterns, Columns: columns, Target: "y"}
+
+	n := 0
+	if len(patterns) > 0 {
+		n = len(patterns[0].Features)
+	}
+	if d.Columns == nil {
+		for j := 0; j < n; j++ {
+			d.Columns = append(d.Columns, fmt.Sprintf("x%d", j))
+		}
+	}
+
+	// binary features
+	d.Types = make([]FeatureType, n)
+	for j := range d.Types {
+		d.Types[j] = BinaryFeature
+		for i := range patterns {
+			if v := patterns[i].Features[j]; v != 0 && v != 1 {
+				d.Types[j] = NumericFeature
+				break
+			}
+		}
+	}
+
+	// kind of target
+	raw, integer, classes := false, true, 0.0
+	for i := range patterns {
+		if len(patterns[i].MultipleExpectation) > 0 {
+			d.TargetType = MultiLabelTarget
+			return d
+		}
+		raw = raw || patterns[i].SingleRawExpectation != ""
+		v := patterns[i].SingleExpectation
+		integer = integer && v >= 0 && v == math.Trunc(v)
+		classes = math.Max(classes, v+1)
+	}
+
+	switch {
+	case raw:
+		d.Labels = labels
+		if d.Labels == nil {
+			d.Labels = &LabelEncoder{}
+		}
+		// encode a copy, leaving patterns of the caller unchanged
+		patterns = append([]Pattern(nil), patterns...)
+		d.Patterns = patterns
+		for i := range patterns {
+			d.Labels.Fit(patterns[i].SingleRawExpectation)
+			patterns[i].SingleExpectation, _ = d.Labels.Encode(patterns[i].SingleRawExpectation)
+		}
+		classes = float64(d.Labels.Len())
+	case !integer:
+		d.TargetType = RegressionTarget
+		return d
+	}
+	d.TargetType = MulticlassTarget
+	if classes <= 2 {
+		d.TargetType = BinaryTarget
+	}
+
+	return d
+
+}
+
+// LoadDatasetFromCSVFile loads a CSV file into a Dataset, as specified by optional CSV settings (see CSVOptions):
+// by default, without header and with the class name of each pattern in the last column.
+// With CSVOptions.Regression the target is a real value, and the dataset a regression one (see RegressionTarget).
+// Errors in the file are reported as *ParseError, with line and column.
+func LoadDatasetFromCSVFile(filePath string, opts ...CSVOptions) (*Dataset, error) {
+
+	var opt CSVOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	patterns, c, err := readCSVPatterns(f, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	d := newDataset(patterns, c.features(), c.labels)
+	if opt.Regression {
+		d.TargetType = RegressionTarget
+	}
+	if name := c.name(c.target); name != "" {
+		d.Target = name
+	}
+	if len(c.encoded) > 0 {
+		d.Categories = c.categories()
+		for j, names := range d.Categories {
+			if names != nil && j < len(d.Types) {
+				d.Types[j] = CategoricalFeature
+			}
+		}
+	}
+	return d, nil
+
+}
+
+// Len returns the number of patterns.
+func (d *Dataset) Len() int {
+	return len(d.Patterns)
+}
+
+// Classes returns class names of a classification dataset ("0", "1", ... without class names), nil otherwise.
+func (d *Dataset) Classes() []string {
+
+	if d.Labels != nil {
+		return d.Labels.Classes
+	}
+	if d.TargetType != BinaryTarget && d.TargetType != MulticlassTarget {
+		return nil
+	}
+
+	k := 0
+	for i := range d.Patterns {
+		if c := int(d.Patterns[i].SingleExpectation) + 1; c > k {
+			k = c
+		}
+	}
+	classes := make([]string, k)
+	for i := range classes {
+		classes[i] = strconv.Itoa(i)
+	}
+	return classes
+
+}
+
+// Decode returns the class name of a predicted value, or the value itself as a string for datasets without
+// class names (or values that are not the index of a class).
+func (d *Dataset) Decode(y float64) string {
+
+	if d.Labels != nil {
+		if c, err := d.Labels.Decode(y); err == nil {
+			return c
+		}
+	}
+	return strconv.FormatFloat(y, 'g', -1, 64)
+
+}
+
+// MLPTrainDataset trains mlp on the classes of dataset d, as MLPTrain.
+func MLPTrainDataset(mlp *MultiLayerNetwork, d *Dataset, epochs int, opts ...TrainOptions) History {
+
+	h, err := MLPTrainDatasetContext(context.Background(), mlp, d, epochs, opts...)
+	logTrainError("MLPTrain", err)
+	return h
+
+}
+
+// MLPTrainDatasetContext is like MLPTrainDataset but stops cleanly when ctx is done, as MLPTrainContext.
+func MLPTrainDatasetContext(ctx context.Context, mlp *MultiLayerNetwork, d *Dataset, epochs int, opts ...TrainOptions) (History, error) {
+
+	classes := d.Classes()
+	if classes == nil {
+		return History{}, fmt.Errorf("neural: MLPTrain needs a classification dataset, got a %v target", d.TargetType)
+	}
+	return MLPTrainContext(ctx, mlp, d.Patterns, classes, epochs, opts...)
+
+}
@@ -0,0 +1,37 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"testing"
+
+)
+
+// #######################################################################################
+
+// TestNewDatasetKeepsPatterns checks that encoding class names does not change patterns of the caller.
+func TestNewDatasetKeepsPatterns(t *testing.T) {
+
+	patterns := []Pattern{
+		{Features: []float64{0.5}, SingleRawExpectation: "b", SingleExpectation: -1},
+		{Features: []float64{1.5}, SingleRawExpectation: "a", SingleExpectation: -1},
+		{Features: []float64{2.5}, SingleRawExpectation: "b", SingleExpectation: -1},
+	}
+
+	d := NewDataset(patterns, nil)
+	for i := range patterns {
+		if patterns[i].SingleExpectation != -1 {
+			t.Errorf("pattern %d of the caller has class %v after encoding", i, patterns[i].SingleExpectation)
+		}
+	}
+	for i, want := range []float64{0, 1, 0} {
+		if c := d.Patterns[i].SingleExpectation; c != want {
+			t.Errorf("pattern %d of the dataset has class %v, want %v", i, c, want)
+		}
+	}
+	if d.TargetType != BinaryTarget {
+		t.Errorf("target of dataset is %v, want binary", d.TargetType)
+	}
+
+}
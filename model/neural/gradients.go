@@ -0,0 +1,236 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+// Gradients represents derivatives of the loss with respect to weights and biases of each layer
+// of a MultiLayerNetwork, accumulated over a number of patterns.
+type Gradients struct {
+
+	// Weights represents derivatives of weights of each layer, flattened neuron by neuron
+	Weights [][]float64
+	// Biases represents derivatives of biases of each layer
+	Biases [][]float64
+	// Count represents number of patterns accumulated
+	Count int
+	// Loss represents sum of errors of patterns accumulated
+	Loss float64
+
+}
+
+// #######################################################################################
+
+// NewGradients create an empty accumulator of derivatives shaped as the network.
+func NewGradients(mlp *MultiLayerNetwork) *Gradients {
+
+	g := &Gradients{Weights: make([][]float64, len(mlp.NeuralLayers)), Biases: make([][]float64, len(mlp.NeuralLayers))}
+
+	// for each layer from first hidden to output
+	for k := 1; k < len(mlp.NeuralLayers); k++ {
+		g.Weights[k] = make([]float64, mlp.NeuralLayers[k].Length * mlp.NeuralLayers[k - 1].Length)
+		g.Biases[k] = make([]float64, mlp.NeuralLayers[k].Length)
+	}
+
+	return g
+
+}
+
+// reuse returns g cleared if it is shaped as the network, a new accumulator otherwise:
+// buffers of derivatives are allocated once, not for each pattern.
+func reuse(g *Gradients, mlp *MultiLayerNetwork) *Gradients {
+
+	if g == nil || len(g.Weights) != len(mlp.NeuralLayers) {
+		return NewGradients(mlp)
+	}
+	for k := 1; k < len(mlp.NeuralLayers); k++ {
+		if len(g.Weights[k]) != mlp.NeuralLayers[k].Length * mlp.NeuralLayers[k - 1].Length || len(g.Biases[k]) != mlp.NeuralLayers[k].Length {
+			return NewGradients(mlp)
+		}
+	}
+	g.Reset()
+	return g
+
+}
+
+// Reset clears accumulated derivatives.
+func (g *Gradients) Reset() {
+
+	for k := range g.Weights {
+		for i := range g.Weights[k] {
+			g.Weights[k][i] = 0.0
+		}
+		for i := range g.Biases[k] {
+			g.Biases[k][i] = 0.0
+		}
+	}
+	g.Count, g.Loss = 0, 0.0
+
+}
+
+// Add accumulates derivatives of another accumulator of the same network.
+func (g *Gradients) Add(o *Gradients) {
+
+	for k := range g.Weights {
+		for i := range g.Weights[k] {
+			g.Weights[k][i] += o.Weights[k][i]
+		}
+		for i := range g.Biases[k] {
+			g.Biases[k][i] += o.Biases[k][i]
+		}
+	}
+	g.Count += o.Count
+	g.Loss += o.Loss
+
+}
+
+// accumulate adds derivatives of the last pattern propagated back, using deltas and outputs of each layer.
+// [mlp:MultiLayerNetwork] network, [r:float64] error of pattern
+func (g *Gradients) accumulate(mlp *MultiLayerNetwork, r float64) {
+
+	// for each layer from first hidden to output:
+	// opposite of focused level deltas * previous level outputs, opposite of deltas for biases
+	for k := 1; k < len(mlp.NeuralLayers); k++ {
+		l := &mlp.NeuralLayers[k]
+		addOuter(g.Weights[k], -1, l.d, mlp.NeuralLayers[k-1].a)
+		axpy(-1, l.d, g.Biases[k])
+	}
+
+	g.Count++
+	g.Loss += r
+
+}
+
+// ComputeBatchGradients executes the network on a batch of patterns at once, with matrix-matrix products,
+// and propagates back the errors, accumulating derivatives of the loss in g without updating weights.
+// It is equivalent to ComputeGradients on each pattern, but for recurrent networks.
+// [mlp:MultiLayerNetwork] network		[s:[]Pattern] input values
+// [o:[][]float64] expected output values, one for each pattern		[g:Gradients] accumulator of derivatives
+// return [y:[]float64] outputs of network, one row for each pattern, valid until next execution
+func ComputeBatchGradients(mlp *MultiLayerNetwork, s []*Pattern, o [][]float64, g *Gradients) (y []float64) {
+
+	n := len(s)
+	if cap(mlp.losses) < n {
+		mlp.losses = make([]float64, n)
+	}
+	losses := mlp.losses[:n]
+	propagateBatch(mlp, s, o, losses)
+
+	// accumulate derivatives of weights and biases of all patterns
+	for _, l := range losses {
+		g.Loss += l
+	}
+	nets := []*MultiLayerNetwork{mlp}
+	for k := 1; k < len(mlp.NeuralLayers); k++ {
+		addBatchGradients(g, k, 0, mlp.NeuralLayers[k].Length, nets)
+	}
+	g.Count += n
+
+	return mlp.NeuralLayers[len(mlp.NeuralLayers)-1].ab
+
+}
+
+// propagateBatch executes the network on a batch of patterns and propagates back the errors, leaving outputs and
+// deltas of each pattern in batch buffers of layers (see addBatchGradients) and the loss of each pattern in losses.
+func propagateBatch(mlp *MultiLayerNetwork, s []*Pattern, o [][]float64, losses []float64) {
+
+	n := len(s)
+	mlp.pack()
+	for k := range mlp.NeuralLayers {
+		mlp.NeuralLayers[k].batch(n)
+	}
+
+	// show patterns to network, context units are 0.5
+	in := &mlp.NeuralLayers[0]
+	for b := range s {
+		row := in.ab[b*in.Length : (b+1)*in.Length]
+		c := copy(row, s[b].Features)
+		for i := c; i < in.Length; i++ {
+			row[i] = 0.5
+		}
+	}
+
+	// forward: for each layers from first hidden to output, weighted inputs of all patterns, then transfer function
+	for k := 1; k < len(mlp.NeuralLayers); k++ {
+		l, prev := &mlp.NeuralLayers[k], &mlp.NeuralLayers[k-1]
+		matMulT(prev.ab, n, prev.Length, l.W, l.Length, l.B, l.zb)
+		for b := 0; b < n; b++ {
+			activate(l.T_func, l.zb[b*l.Length:(b+1)*l.Length], l.ab[b*l.Length:(b+1)*l.Length])
+		}
+	}
+
+	// backward: deltas of output layer for each pattern, then of previous layers
+	ol := &mlp.NeuralLayers[len(mlp.NeuralLayers)-1]
+	loss := mlp.lossFunc()
+	m := ol.Length
+	for b := 0; b < n; b++ {
+		yb := ol.ab[b*m : (b+1)*m]
+		outputDeltas(loss, ol.T_func, ol.zb[b*m:(b+1)*m], yb, o[b], ol.eb[b*m:(b+1)*m], ol.db[b*m:(b+1)*m])
+		losses[b] = loss.Loss(yb, o[b])
+	}
+	for k := len(mlp.NeuralLayers) - 2; k >= 1; k-- {
+		l, next := &mlp.NeuralLayers[k], &mlp.NeuralLayers[k+1]
+		matMul(next.db, n, next.Length, next.W, l.Length, l.eb)
+		for b := 0; b < n; b++ {
+			r := l.Length
+			derive(l.T_func, l.zb[b*r:(b+1)*r], l.ab[b*r:(b+1)*r], l.eb[b*r:(b+1)*r], l.db[b*r:(b+1)*r])
+		}
+	}
+
+}
+
+// addBatchGradients adds to g derivatives of neurons lo to hi of layer k for the batches propagated by nets
+// (see propagateBatch), pattern by pattern in order of nets: the derivative of each weight is the same sum
+// whether a batch is propagated by a network or split among more, and whatever neurons are accumulated at a time.
+func addBatchGradients(g *Gradients, k int, lo int, hi int, nets []*MultiLayerNetwork) {
+
+	for _, net := range nets {
+		l, prev := &net.NeuralLayers[k], &net.NeuralLayers[k-1]
+		n := len(l.db) / l.Length
+		addMatTMul(g.Weights[k], -1, l.db, n, l.Length, prev.ab, prev.Length, lo, hi)
+		for b := 0; b < n; b++ {
+			axpy(-1, l.db[b*l.Length+lo:b*l.Length+hi], g.Biases[k][lo:hi])
+		}
+	}
+
+}
+
+// ApplyGradients updates weights and biases of each layer with the mean of accumulated derivatives,
+// using optimizer of network, or plain SGD if not specified.
+// [mlp:MultiLayerNetwork] network, [g:Gradients] accumulated derivatives, [lr:float64] learning rate
+func ApplyGradients(mlp *MultiLayerNetwork, g *Gradients, lr float64) {
+
+	if g.Count == 0 {
+		return
+	}
+
+	// mean of derivatives
+	c := 1.0 / float64(g.Count)
+	mlp.pack()
+
+	// plain SGD, update in place
+	if mlp.Optimizer == nil {
+		for k := 1; k < len(mlp.NeuralLayers); k++ {
+			l := &mlp.NeuralLayers[k]
+			axpy(-lr*c, g.Weights[k], l.W)
+			axpy(-lr*c, g.Biases[k], l.B)
+			l.syncBiases()
+		}
+		return
+	}
+
+	mlp.Optimizer.Step()
+	mlp.mean = reuse(mlp.mean, mlp)
+
+	// for each layer, weights are group 2k and biases group 2k+1
+	for k := 1; k < len(mlp.NeuralLayers); k++ {
+
+		l := &mlp.NeuralLayers[k]
+		gw, gb := mlp.mean.Weights[k], mlp.mean.Biases[k]
+		axpy(c, g.Weights[k], gw)
+		axpy(c, g.Biases[k], gb)
+
+		mlp.Optimizer.Update(2 * k, l.W, gw, lr)
+		mlp.Optimizer.Update(2 * k + 1, l.B, gb, lr)
+		l.syncBiases()
+
+	}
+
+}
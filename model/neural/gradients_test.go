@@ -0,0 +1,29 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"math/rand"
+	"testing"
+
+)
+
+// #######################################################################################
+
+// TestBackPropagateAllocs checks that online training reuses buffers of derivatives, with and without optimizer.
+func TestBackPropagateAllocs(t *testing.T) {
+
+	for _, o := range []Optimizer{nil, Adam(0.9, 0.999)} {
+
+		mlp := PrepareMLPNet([]int{60, 20, 2}, 0.1, Sigmoid, rand.New(rand.NewSource(1)))
+		mlp.Optimizer = o
+		p, target := &Pattern{Features: make([]float64, 60)}, []float64{1, 0}
+
+		if n := testing.AllocsPerRun(100, func() { BackPropagate(&mlp, p, target) }); n != 0 {
+			t.Errorf("BackPropagate with optimizer %v allocates %v times per pattern", o, n)
+		}
+
+	}
+
+}
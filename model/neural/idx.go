@@ -0,0 +1,218 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+)
+
+// types of values in IDX files, from the third byte of the magic number
+const (
+	idxUint8   = 0x08
+	idxInt8    = 0x09
+	idxInt16   = 0x0B
+	idxInt32   = 0x0C
+	idxFloat32 = 0x0D
+	idxFloat64 = 0x0E
+)
+
+// idxMaxSize represents the largest number of values read from an IDX file
+const idxMaxSize = 1 << 31
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+// LoadDatasetFromIDXFiles loads images and labels in IDX format (the format of the MNIST database), gzip
+// compressed or not, into a Dataset: each image is a pattern, with pixels as features in row order, and its
+// label is its class (classes are "0", "1", ...). Pixels are not scaled.
+func LoadDatasetFromIDXFiles(imagesPath string, labelsPath string) (*Dataset, error) {
+
+	images, dims, err := readIDXFile(imagesPath)
+	if err != nil {
+		return nil, err
+	}
+	labels, ldims, err := readIDXFile(labelsPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(dims) < 2 || len(ldims) != 1 || dims[0] != ldims[0] {
+		return nil, fmt.Errorf("neural: %d images of dimensions %v, %d labels of dimensions %v", dims[0], dims[1:], ldims[0], ldims[1:])
+	}
+
+	n := 1
+	for _, d := range dims[1:] {
+		n *= d
+	}
+	patterns := make([]Pattern, dims[0])
+	for i := range patterns {
+		patterns[i] = Pattern{Features: images[i*n : (i+1)*n : (i+1)*n], SingleExpectation: labels[i]}
+	}
+
+	log.WithFields(log.Fields{
+		"level":      "info",
+		"place":      "patterns",
+		"method":     "LoadDatasetFromIDXFiles",
+		"readData":   len(patterns),
+		"dimensions": dims[1:],
+	}).Info("File reading completed.")
+
+	return newDataset(patterns, nil, nil), nil
+
+}
+
+// readIDXFile reads the IDX file at filePath, see ReadIDX.
+func readIDXFile(filePath string) ([]float64, []int, error) {
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	values, dims, err := ReadIDX(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", filePath, err)
+	}
+	return values, dims, nil
+
+}
+
+// ReadIDX reads an array in IDX format from r, gzip compressed or not.
+// It returns its values, in row order, and its dimensions.
+func ReadIDX(r io.Reader) ([]float64, []int, error) {
+
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer zr.Close()
+		br = bufio.NewReader(zr)
+	}
+
+	// magic number: two zero bytes, type of values and number of dimensions
+	var magic [4]byte
+	if _, err := io.ReadFull(br, magic[:]); err != nil {
+		return nil, nil, fmt.Errorf("neural: reading IDX header: %v", err)
+	}
+	if magic[0] != 0 || magic[1] != 0 || magic[3] == 0 {
+		return nil, nil, fmt.Errorf("neural: invalid IDX magic number %x", magic)
+	}
+
+	dims := make([]int, magic[3])
+	size := 1
+	for i := range dims {
+		var d uint32
+		if err := binary.Read(br, binary.BigEndian, &d); err != nil {
+			return nil, nil, fmt.Errorf("neural: reading IDX dimensions: %v", err)
+		}
+		dims[i] = int(d)
+		if size *= dims[i]; dims[i] > idxMaxSize || size > idxMaxSize {
+			return nil, nil, fmt.Errorf("neural: IDX array of dimensions %v too large", dims[:i+1])
+		}
+	}
+
+	values := make([]float64, size)
+	var err error
+	switch magic[2] {
+	case idxUint8:
+		b := make([]uint8, size)
+		if _, err = io.ReadFull(br, b); err == nil {
+			for i, v := range b {
+				values[i] = float64(v)
+			}
+		}
+	case idxInt8:
+		b := make([]int8, size)
+		if err = binary.Read(br, binary.BigEndian, b); err == nil {
+			for i, v := range b {
+				values[i] = float64(v)
+			}
+		}
+	case idxInt16:
+		b := make([]int16, size)
+		if err = binary.Read(br, binary.BigEndian, b); err == nil {
+			for i, v := range b {
+				values[i] = float64(v)
+			}
+		}
+	case idxInt32:
+		b := make([]int32, size)
+		if err = binary.Read(br, binary.BigEndian, b); err == nil {
+			for i, v := range b {
+				values[i] = float64(v)
+			}
+		}
+	case idxFloat32:
+		b := make([]float32, size)
+		if err = binary.Read(br, binary.BigEndian, b); err == nil {
+			for i, v := range b {
+				values[i] = float64(v)
+			}
+		}
+	case idxFloat64:
+		err = binary.Read(br, binary.BigEndian, values)
+	default:
+		return nil, nil, fmt.Errorf("neural: unknown IDX type %#x", magic[2])
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("neural: reading IDX values: %v", err)
+	}
+
+	return values, dims, nil
+
+}
+
+// WriteIDX writes values, an array of dimensions dims in row order, to w in IDX format: as unsigned bytes
+// if they all are integers from 0 to 255, as float64 otherwise.
+func WriteIDX(w io.Writer, values []float64, dims []int) error {
+
+	size := 1
+	for _, d := range dims {
+		size *= d
+	}
+	if len(dims) == 0 || len(dims) > 255 || size != len(values) {
+		return fmt.Errorf("neural: %d values cannot be an IDX array of dimensions %v", len(values), dims)
+	}
+
+	t := byte(idxUint8)
+	for _, v := range values {
+		if v < 0 || v > 255 || v != math.Trunc(v) {
+			t = idxFloat64
+			break
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write([]byte{0, 0, t, byte(len(dims))})
+	for _, d := range dims {
+		binary.Write(bw, binary.BigEndian, uint32(d))
+	}
+	if t == idxUint8 {
+		for _, v := range values {
+			bw.WriteByte(byte(v))
+		}
+	} else {
+		binary.Write(bw, binary.BigEndian, values)
+	}
+	return bw.Flush()
+
+}
@@ -0,0 +1,214 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"math"
+	"math/rand"
+
+)
+
+// Initializer represents a strategy to set the initial weights (or biases) of a layer.
+type Initializer interface {
+
+	// Init sets values of w, the weights of a layer with fanIn inputs and fanOut neurons
+	// (row i holds the fanIn weights of neuron i), or its fanOut biases, drawing from rng
+	Init(w []float64, fanIn int, fanOut int, rng *rand.Rand)
+
+}
+
+// InitializerFunc implements Initializer with a user supplied function.
+type InitializerFunc func(w []float64, fanIn int, fanOut int, rng *rand.Rand)
+
+// built-in initializers
+var (
+
+	// XavierUniform (Glorot) draws from U(-l, l) with l = sqrt(6 / (fanIn + fanOut)), for sigmoid and tanh layers
+	XavierUniform Initializer = varianceScaling{scale: 1, fanAvg: true, uniform: true}
+	// XavierNormal (Glorot) draws from N(0, 2 / (fanIn + fanOut)), for sigmoid and tanh layers
+	XavierNormal Initializer = varianceScaling{scale: 1, fanAvg: true}
+	// HeUniform (Kaiming) draws from U(-l, l) with l = sqrt(6 / fanIn), for ReLU layers
+	HeUniform Initializer = varianceScaling{scale: 2, uniform: true}
+	// HeNormal (Kaiming) draws from N(0, 2 / fanIn), for ReLU layers
+	HeNormal Initializer = varianceScaling{scale: 2}
+	// LeCunUniform draws from U(-l, l) with l = sqrt(3 / fanIn)
+	LeCunUniform Initializer = varianceScaling{scale: 1, uniform: true}
+	// LeCunNormal draws from N(0, 1 / fanIn)
+	LeCunNormal Initializer = varianceScaling{scale: 1}
+
+	// Zeros sets all values to 0, usual for biases
+	Zeros Initializer = Constant(0)
+
+)
+
+// #######################################################################################
+
+// Constant returns an initializer that sets all values to v.
+func Constant(v float64) Initializer {
+	return constant{v: v}
+}
+
+// Normal returns an initializer that draws from N(0, std^2).
+// Normal(SCALING_FACTOR) is the initialization of RandomNeuronInit, used when none is given.
+func Normal(std float64) Initializer {
+	return normal{std: std}
+}
+
+// Uniform returns an initializer that draws from U(-limit, limit).
+func Uniform(limit float64) Initializer {
+	return uniform{limit: limit}
+}
+
+// Orthogonal returns an initializer that makes the weight matrix (semi) orthogonal, scaled by gain:
+// rows are orthonormal if fanOut <= fanIn, columns otherwise.
+func Orthogonal(gain float64) Initializer {
+	return orthogonal{gain: gain}
+}
+
+func (f InitializerFunc) Init(w []float64, fanIn int, fanOut int, rng *rand.Rand) {
+	f(w, fanIn, fanOut, rng)
+}
+
+// initializers returns initializers of weights and biases from optional ones, nil if missing.
+func initializers(init []Initializer) (wi Initializer, bi Initializer) {
+
+	if len(init) > 0 {
+		wi = init[0]
+	}
+	if len(init) > 1 {
+		bi = init[1]
+	}
+	return
+
+}
+
+// initLayer sets weights and biases of the n neurons of layer l, with p inputs each, using initializers wi and bi.
+func initLayer(l *NeuralLayer, p int, wi Initializer, bi Initializer, rng *rand.Rand) {
+
+	n := l.Length
+
+	w := make([]float64, n*p)
+	wi.Init(w, p, n, rng)
+	b := make([]float64, n)
+	bi.Init(b, p, n, rng)
+
+	for i := range l.NeuronUnits {
+		l.NeuronUnits[i].Weights = w[i*p : (i+1)*p : (i+1)*p]
+		l.NeuronUnits[i].Bias = b[i]
+	}
+	l.W, l.B = w, b
+
+}
+
+// different type of initializer
+
+type constant struct{ v float64 }
+
+func (c constant) Init(w []float64, fanIn int, fanOut int, rng *rand.Rand) {
+
+	for i := range w {
+		w[i] = c.v
+	}
+
+}
+
+type normal struct{ std float64 }
+
+func (n normal) Init(w []float64, fanIn int, fanOut int, rng *rand.Rand) {
+
+	for i := range w {
+		w[i] = rng.NormFloat64() * n.std
+	}
+
+}
+
+type uniform struct{ limit float64 }
+
+func (u uniform) Init(w []float64, fanIn int, fanOut int, rng *rand.Rand) {
+
+	for i := range w {
+		w[i] = (2*rng.Float64() - 1) * u.limit
+	}
+
+}
+
+// varianceScaling draws values with variance scale / fanIn, or scale / ((fanIn + fanOut) / 2) if fanAvg,
+// from a normal or a uniform distribution.
+type varianceScaling struct {
+	scale   float64
+	fanAvg  bool
+	uniform bool
+}
+
+func (v varianceScaling) Init(w []float64, fanIn int, fanOut int, rng *rand.Rand) {
+
+	fan := float64(fanIn)
+	if v.fanAvg {
+		fan = float64(fanIn+fanOut) / 2
+	}
+	if fan < 1 {
+		fan = 1
+	}
+
+	std := math.Sqrt(v.scale / fan)
+	if v.uniform {
+		// uniform in [-l, l] has variance l^2 / 3
+		uniform{limit: std * math.Sqrt(3)}.Init(w, fanIn, fanOut, rng)
+		return
+	}
+	normal{std: std}.Init(w, fanIn, fanOut, rng)
+
+}
+
+type orthogonal struct{ gain float64 }
+
+func (o orthogonal) Init(w []float64, fanIn int, fanOut int, rng *rand.Rand) {
+
+	// biases, or any vector, are taken as a single row
+	rows, cols := fanOut, fanIn
+	if rows*cols != len(w) {
+		rows, cols = 1, len(w)
+	}
+	if len(w) == 0 {
+		return
+	}
+
+	// orthonormalize the shorter side of a random gaussian matrix
+	n, m := rows, cols
+	at := func(i, j int) *float64 { return &w[i*cols+j] }
+	if rows > cols {
+		n, m = cols, rows
+		at = func(i, j int) *float64 { return &w[j*cols+i] }
+	}
+
+	for i := range w {
+		w[i] = rng.NormFloat64()
+	}
+
+	// modified Gram-Schmidt on the n vectors of length m
+	for i := 0; i < n; i++ {
+		for k := 0; k < i; k++ {
+			d := 0.0
+			for j := 0; j < m; j++ {
+				d += *at(i, j) * *at(k, j)
+			}
+			for j := 0; j < m; j++ {
+				*at(i, j) -= d * *at(k, j)
+			}
+		}
+		norm := 0.0
+		for j := 0; j < m; j++ {
+			norm += *at(i, j) * *at(i, j)
+		}
+		norm = math.Sqrt(norm)
+		for j := 0; j < m; j++ {
+			*at(i, j) /= norm
+		}
+	}
+
+	for i := range w {
+		w[i] *= o.gain
+	}
+
+}
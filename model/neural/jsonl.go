@@ -0,0 +1,272 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+)
+
+// JSONLOptions represents settings of the JSON Lines loader. The zero value reads the last key of the first
+// record as target, and its other keys as features.
+type JSONLOptions struct {
+
+	// Target represents the key of expected value (empty is the last key of the first record)
+	Target string
+	// Features represents the keys of features, in order (nil is the keys of the first record, but the target)
+	Features []string
+
+}
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+// LoadDatasetFromJSONLFile loads a JSON Lines file, one JSON object per line, into a Dataset, as specified by
+// optional settings (see JSONLOptions). Features are numbers, booleans (0 or 1) or null (NaN), and are NaN if
+// missing. The target is a class name (string), a number, or an array of numbers for a multi-label target;
+// rows with null or missing target are skipped. Errors in the file are reported as *ParseError, with line
+// and column (the feature, from 1).
+func LoadDatasetFromJSONLFile(filePath string, opts ...JSONLOptions) (*Dataset, error) {
+
+	var opt JSONLOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readJSONL(f, opt)
+
+}
+
+// readJSONL reads a dataset in JSON Lines format from r.
+func readJSONL(r io.Reader, opt JSONLOptions) (*Dataset, error) {
+
+	var patterns []Pattern
+	target, features := opt.Target, opt.Features
+	kind := byte(0)
+
+	err := readLines(r, func(line int, s string) error {
+
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		keys, values, err := jsonObject(s)
+		if err != nil {
+			return &ParseError{Line: line, Err: err}
+		}
+
+		// keys of the first record
+		if target == "" {
+			if len(keys) == 0 {
+				return &ParseError{Line: line, Err: errors.New("empty record, the target cannot be found")}
+			}
+			target = keys[len(keys)-1]
+		}
+		if features == nil {
+			features = []string{}
+			for _, k := range keys {
+				if k != target {
+					features = append(features, k)
+				}
+			}
+		}
+
+		record := make(map[string]json.RawMessage, len(keys))
+		for i, k := range keys {
+			record[k] = values[i]
+		}
+
+		// expected value, of the same kind in all records
+		p := Pattern{}
+		raw := strings.TrimSpace(string(record[target]))
+		if raw == "" || raw == "null" {
+			jsonlWarn(&ParseError{Line: line, Name: target, Err: errors.New("missing expected value")}, "Row skipped.")
+			return nil
+		}
+		k := raw[0]
+		if k != '"' && k != '[' {
+			k = '0'
+		}
+		if kind != 0 && k != kind {
+			return &ParseError{Line: line, Name: target, Err: fmt.Errorf("expected value %s is not of the kind of previous ones", raw)}
+		}
+		kind = k
+		switch kind {
+		case '"':
+			err = json.Unmarshal(record[target], &p.SingleRawExpectation)
+		case '[':
+			err = json.Unmarshal(record[target], &p.MultipleExpectation)
+		default:
+			err = json.Unmarshal(record[target], &p.SingleExpectation)
+		}
+		if err != nil {
+			return &ParseError{Line: line, Name: target, Err: fmt.Errorf("invalid expected value %s", raw)}
+		}
+
+		// features
+		p.Features = make([]float64, len(features))
+		for j, f := range features {
+			v, ok := record[f]
+			if !ok {
+				p.Features[j] = math.NaN()
+				continue
+			}
+			if p.Features[j], err = jsonValue(v); err != nil {
+				return &ParseError{Line: line, Column: j + 1, Name: f, Err: err}
+			}
+		}
+
+		patterns = append(patterns, p)
+		return nil
+
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d := newDataset(patterns, features, nil)
+	if target != "" {
+		d.Target = target
+	}
+
+	log.WithFields(log.Fields{
+		"level":    "info",
+		"place":    "patterns",
+		"method":   "readJSONL",
+		"readData": len(patterns),
+	}).Info("File reading completed.")
+
+	return d, nil
+
+}
+
+// jsonObject returns keys and values of the JSON object in s, in order.
+func jsonObject(s string) ([]string, []json.RawMessage, error) {
+
+	dec := json.NewDecoder(strings.NewReader(s))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, nil, errors.New("not a JSON object")
+	}
+
+	var keys []string
+	var values []json.RawMessage
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, nil, err
+		}
+		keys, values = append(keys, t.(string)), append(values, v)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, err
+	}
+	return keys, values, nil
+
+}
+
+// jsonValue returns the value of a feature: a number, a boolean (0 or 1) or null (NaN).
+func jsonValue(v json.RawMessage) (float64, error) {
+
+	switch s := strings.TrimSpace(string(v)); s {
+	case "null":
+		return math.NaN(), nil
+	case "true":
+		return 1, nil
+	case "false":
+		return 0, nil
+	}
+	var f float64
+	if err := json.Unmarshal(v, &f); err != nil {
+		return 0, fmt.Errorf("%s is not a number", v)
+	}
+	return f, nil
+
+}
+
+// jsonlWarn logs err, found reading a JSON Lines file.
+func jsonlWarn(err error, msg string) {
+
+	log.WithFields(log.Fields{
+		"level":  "warning",
+		"place":  "patterns",
+		"method": "readJSONL",
+		"error":  err,
+	}).Warn(msg)
+
+}
+
+// WriteJSONL writes the dataset to w in JSON Lines format, one object per pattern with features by name and
+// the target: class names for classification targets with names, arrays of expected outputs for multi-label
+// ones, numbers otherwise. Missing values are written as null.
+func (d *Dataset) WriteJSONL(w io.Writer) error {
+
+	number := func(v float64) string {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "null"
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	key := func(k string) string {
+		b, _ := json.Marshal(k)
+		return string(b)
+	}
+
+	bw := bufio.NewWriter(w)
+	for i := range d.Patterns {
+
+		p := &d.Patterns[i]
+		bw.WriteByte('{')
+		for j, v := range p.Features {
+			k := fmt.Sprintf("x%d", j)
+			if j < len(d.Columns) {
+				k = d.Columns[j]
+			}
+			fmt.Fprintf(bw, "%s:%s,", key(k), number(v))
+		}
+
+		bw.WriteString(key(d.Target) + ":")
+		switch {
+		case d.TargetType == MultiLabelTarget:
+			vs := make([]string, len(p.MultipleExpectation))
+			for c, v := range p.MultipleExpectation {
+				vs[c] = number(v)
+			}
+			bw.WriteString("[" + strings.Join(vs, ",") + "]")
+		case d.Labels != nil:
+			bw.WriteString(key(d.Decode(p.SingleExpectation)))
+		default:
+			bw.WriteString(number(p.SingleExpectation))
+		}
+		bw.WriteString("}\n")
+
+	}
+	return bw.Flush()
+
+}
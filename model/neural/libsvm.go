@@ -0,0 +1,277 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+)
+
+// LIBSVMOptions represents settings of the LIBSVM (SVMlight) loader. The zero value reads one-based indexes
+// of features, as many features as the highest index found, and integer labels as classes.
+type LIBSVMOptions struct {
+
+	// Features represents the number of features (0 is the highest index found)
+	Features int
+	// ZeroBased represents if indexes of features start from 0 instead of 1
+	ZeroBased bool
+	// Regression represents if labels are real values, even if they are all integers
+	Regression bool
+
+}
+
+// libsvmEntry represents a non zero feature of a LIBSVM line.
+type libsvmEntry struct {
+	j int
+	v float64
+}
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+// LoadDatasetFromLIBSVMFile loads a file in the sparse LIBSVM (SVMlight) format into a Dataset, as specified by
+// optional settings (see LIBSVMOptions). Each line holds a label and the non zero features as index:value pairs,
+// missing features are 0. Integer labels are class names, encoded in numeric order ("-1" before "+1"), real ones
+// are a regression target; comma separated labels ("1,3") are a multi-label target, with an output per label.
+// Errors in the file are reported as *ParseError, with line and column (the field of the line).
+func LoadDatasetFromLIBSVMFile(filePath string, opts ...LIBSVMOptions) (*Dataset, error) {
+
+	var opt LIBSVMOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readLIBSVM(f, opt)
+
+}
+
+// readLIBSVM reads a dataset in LIBSVM format from r.
+func readLIBSVM(r io.Reader, opt LIBSVMOptions) (*Dataset, error) {
+
+	var rows [][]libsvmEntry
+	var labels [][]string
+	multi, real, n := false, opt.Regression, opt.Features
+
+	err := readLines(r, func(line int, s string) error {
+
+		// skip comments and empty lines
+		if i := strings.IndexByte(s, '#'); i >= 0 {
+			s = s[:i]
+		}
+		fields := strings.Fields(s)
+		if len(fields) == 0 {
+			return nil
+		}
+
+		// labels, none if the line starts with a feature
+		var ls []string
+		if !strings.Contains(fields[0], ":") {
+			ls = strings.Split(fields[0], ",")
+			multi = multi || len(ls) > 1
+			for _, l := range ls {
+				v, err := strconv.ParseFloat(l, 64)
+				if err != nil {
+					return &ParseError{Line: line, Column: 1, Err: fmt.Errorf("label %q is not a number", l)}
+				}
+				real = real || v != math.Trunc(v)
+			}
+			fields = fields[1:]
+		} else {
+			multi = true
+		}
+
+		// features, skipping query ids of ranking files
+		first := 1
+		if ls != nil {
+			first = 2
+		}
+		var row []libsvmEntry
+		for k, f := range fields {
+			column := first + k
+			if strings.HasPrefix(f, "qid:") {
+				continue
+			}
+			i := strings.IndexByte(f, ':')
+			if i < 0 {
+				return &ParseError{Line: line, Column: column, Err: fmt.Errorf("%q is not an index:value pair", f)}
+			}
+			j, err := strconv.Atoi(f[:i])
+			if !opt.ZeroBased {
+				j--
+			}
+			if err != nil || j < 0 || (opt.Features > 0 && j >= opt.Features) {
+				return &ParseError{Line: line, Column: column, Err: fmt.Errorf("invalid feature index %q", f[:i])}
+			}
+			v, err := strconv.ParseFloat(f[i+1:], 64)
+			if err != nil {
+				return &ParseError{Line: line, Column: column, Err: fmt.Errorf("%q is not a number", f[i+1:])}
+			}
+			if j >= n {
+				n = j + 1
+			}
+			row = append(row, libsvmEntry{j, v})
+		}
+
+		rows = append(rows, row)
+		labels = append(labels, ls)
+		return nil
+
+	})
+	if err != nil {
+		return nil, err
+	}
+	if multi && real {
+		return nil, errors.New("neural: multi-label LIBSVM files need integer labels")
+	}
+
+	// class names, in numeric order
+	var enc *LabelEncoder
+	if !real {
+		enc = &LabelEncoder{}
+		for _, ls := range labels {
+			enc.Fit(ls...)
+		}
+		sort.SliceStable(enc.Classes, func(a, b int) bool {
+			x, _ := strconv.ParseFloat(enc.Classes[a], 64)
+			y, _ := strconv.ParseFloat(enc.Classes[b], 64)
+			return x < y
+		})
+		enc.reindex()
+	}
+
+	patterns := make([]Pattern, len(rows))
+	for i, row := range rows {
+		p := &patterns[i]
+		p.Features = make([]float64, n)
+		for _, e := range row {
+			p.Features[e.j] = e.v
+		}
+		switch {
+		case multi:
+			p.MultipleExpectation = make([]float64, enc.Len())
+			for _, l := range labels[i] {
+				c, _ := enc.Encode(l)
+				p.MultipleExpectation[int(c)] = 1
+			}
+		case real:
+			p.SingleExpectation, _ = strconv.ParseFloat(labels[i][0], 64)
+		default:
+			p.SingleRawExpectation = labels[i][0]
+		}
+	}
+
+	d := newDataset(patterns, nil, enc)
+	switch {
+	case multi:
+		d.TargetType, d.Labels = MultiLabelTarget, enc
+	case real:
+		d.TargetType = RegressionTarget
+	}
+
+	log.WithFields(log.Fields{
+		"level":    "info",
+		"place":    "patterns",
+		"method":   "readLIBSVM",
+		"readData": len(patterns),
+		"features": n,
+	}).Info("File reading completed.")
+
+	return d, nil
+
+}
+
+// WriteLIBSVM writes the dataset to w in LIBSVM format, with one-based indexes of non zero features. Labels are
+// class names if they are all numbers, indexes of classes otherwise; multi-label targets are written as the
+// comma separated names (or indexes) of expected outputs.
+func (d *Dataset) WriteLIBSVM(w io.Writer) error {
+
+	// LIBSVM labels are numbers
+	names := d.Labels != nil
+	if names {
+		for _, c := range d.Labels.Classes {
+			if _, err := strconv.ParseFloat(c, 64); err != nil {
+				names = false
+				break
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for i := range d.Patterns {
+
+		p := &d.Patterns[i]
+		switch {
+		case d.TargetType == MultiLabelTarget:
+			var ls []string
+			for c, v := range p.MultipleExpectation {
+				if v != 0 {
+					if names && c < d.Labels.Len() {
+						ls = append(ls, d.Labels.Classes[c])
+					} else {
+						ls = append(ls, strconv.Itoa(c))
+					}
+				}
+			}
+			bw.WriteString(strings.Join(ls, ","))
+		case names:
+			bw.WriteString(d.Decode(p.SingleExpectation))
+		default:
+			bw.WriteString(strconv.FormatFloat(p.SingleExpectation, 'g', -1, 64))
+		}
+
+		for j, v := range p.Features {
+			if v != 0 {
+				fmt.Fprintf(bw, " %d:%s", j+1, strconv.FormatFloat(v, 'g', -1, 64))
+			}
+		}
+		bw.WriteByte('\n')
+
+	}
+	return bw.Flush()
+
+}
+
+// readLines calls f with each line of r (without line terminators) and its number from 1, until f returns an error.
+func readLines(r io.Reader, f func(line int, s string) error) error {
+
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		s, err := br.ReadString('\n')
+		if len(s) > 0 {
+			if err := f(line, strings.TrimRight(s, "\r\n")); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+}
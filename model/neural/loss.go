@@ -0,0 +1,261 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"fmt"
+	"math"
+
+)
+
+// Loss represents a named error function between network outputs and expected outputs.
+type Loss interface {
+
+	// Name returns the name used to serialize the loss
+	Name() string
+	// Loss computes the error between outputs y and expected outputs t
+	Loss(y []float64, t []float64) float64
+	// Gradient computes g, the derivative of the loss with respect to outputs y
+	Gradient(y []float64, t []float64, g []float64)
+
+}
+
+// FusedLoss represents a loss able to compute its derivative with respect to the weighted inputs
+// of the output layer directly, for some activation, which is simpler and numerically stable.
+type FusedLoss interface {
+
+	Loss
+
+	// FusedGradient computes g, the derivative of the loss with respect to pre-activations z
+	// of an output layer with activation a and outputs y.
+	// It returns false if the loss can not be fused with a.
+	FusedGradient(a Activation, z []float64, y []float64, t []float64, g []float64) bool
+
+}
+
+const (
+
+	// DefaultHuberDelta is the threshold between quadratic and linear zone used by the "huber" loss
+	DefaultHuberDelta = 1.0
+
+	// epsilon clips probabilities before log
+	epsilon = 1e-15
+
+)
+
+// built-in losses
+var (
+
+	// MeanSquaredError is the mean of squared differences
+	MeanSquaredError Loss = mse{}
+	// CrossEntropy is the categorical cross-entropy, for softmax (or sigmoid) outputs and one-hot expected outputs
+	CrossEntropy Loss = crossEntropy{}
+	// BinaryCrossEntropy is the mean of per unit binary cross-entropy, for sigmoid outputs and 0 / 1 expected outputs
+	BinaryCrossEntropy Loss = binaryCrossEntropy{}
+	// Hinge is the mean hinge loss, expected outputs 0 (or -1) and 1 are taken as -1 and 1
+	Hinge Loss = hinge{}
+
+)
+
+// #######################################################################################
+
+// Huber returns the Huber loss, quadratic for errors below delta and linear above.
+func Huber(delta float64) Loss {
+	return huber{delta: delta}
+}
+
+// LossByName returns the built-in loss with the given name.
+// Huber loss with a custom threshold can be requested as "huber(2.5)".
+func LossByName(name string) (Loss, error) {
+
+	for _, l := range []Loss{MeanSquaredError, CrossEntropy, BinaryCrossEntropy, Hinge, Huber(DefaultHuberDelta)} {
+		if l.Name() == name {
+			return l, nil
+		}
+	}
+
+	var d float64
+	if _, err := fmt.Sscanf(name, "huber(%g)", &d); err == nil {
+		return Huber(d), nil
+	}
+
+	return nil, fmt.Errorf("neural: unknown loss %q", name)
+
+}
+
+// different type of loss function
+
+type mse struct{}
+
+func (mse) Name() string { return "mse" }
+
+func (mse) Loss(y []float64, t []float64) float64 {
+
+	r := 0.0
+	for i := range y {
+		r += (y[i] - t[i]) * (y[i] - t[i])
+	}
+	return r / float64(len(y))
+
+}
+
+func (mse) Gradient(y []float64, t []float64, g []float64) {
+
+	for i := range y {
+		g[i] = 2 * (y[i] - t[i]) / float64(len(y))
+	}
+
+}
+
+type crossEntropy struct{}
+
+func (crossEntropy) Name() string { return "cross_entropy" }
+
+func (crossEntropy) Loss(y []float64, t []float64) float64 {
+
+	r := 0.0
+	for i := range y {
+		if t[i] != 0 {
+			r -= t[i] * math.Log(math.Max(y[i], epsilon))
+		}
+	}
+	return r
+
+}
+
+func (crossEntropy) Gradient(y []float64, t []float64, g []float64) {
+
+	for i := range y {
+		g[i] = -t[i] / math.Max(y[i], epsilon)
+	}
+
+}
+
+func (crossEntropy) FusedGradient(a Activation, z []float64, y []float64, t []float64, g []float64) bool {
+
+	switch a.Name() {
+	case Softmax.Name():
+		// expected outputs sum to 1
+		s := 0.0
+		for i := range t {
+			s += t[i]
+		}
+		for i := range y {
+			g[i] = s*y[i] - t[i]
+		}
+	case Sigmoid.Name():
+		for i := range y {
+			g[i] = -t[i] * (1 - y[i])
+		}
+	default:
+		return false
+	}
+	return true
+
+}
+
+type binaryCrossEntropy struct{}
+
+func (binaryCrossEntropy) Name() string { return "binary_cross_entropy" }
+
+func (binaryCrossEntropy) Loss(y []float64, t []float64) float64 {
+
+	r := 0.0
+	for i := range y {
+		p := math.Min(math.Max(y[i], epsilon), 1-epsilon)
+		r -= t[i]*math.Log(p) + (1-t[i])*math.Log(1-p)
+	}
+	return r / float64(len(y))
+
+}
+
+func (binaryCrossEntropy) Gradient(y []float64, t []float64, g []float64) {
+
+	for i := range y {
+		p := math.Min(math.Max(y[i], epsilon), 1-epsilon)
+		g[i] = (p - t[i]) / (p * (1 - p)) / float64(len(y))
+	}
+
+}
+
+func (binaryCrossEntropy) FusedGradient(a Activation, z []float64, y []float64, t []float64, g []float64) bool {
+
+	if a.Name() != Sigmoid.Name() {
+		return false
+	}
+	for i := range y {
+		g[i] = (y[i] - t[i]) / float64(len(y))
+	}
+	return true
+
+}
+
+type huber struct{ delta float64 }
+
+func (l huber) Name() string {
+
+	if l.delta == DefaultHuberDelta {
+		return "huber"
+	}
+	return fmt.Sprintf("huber(%g)", l.delta)
+
+}
+
+func (l huber) Loss(y []float64, t []float64) float64 {
+
+	r := 0.0
+	for i := range y {
+		d := math.Abs(y[i] - t[i])
+		if d <= l.delta {
+			r += 0.5 * d * d
+		} else {
+			r += l.delta * (d - 0.5*l.delta)
+		}
+	}
+	return r / float64(len(y))
+
+}
+
+func (l huber) Gradient(y []float64, t []float64, g []float64) {
+
+	for i := range y {
+		g[i] = math.Max(-l.delta, math.Min(l.delta, y[i]-t[i])) / float64(len(y))
+	}
+
+}
+
+type hinge struct{}
+
+func (hinge) Name() string { return "hinge" }
+
+// sign maps expected output to -1 or 1
+func (hinge) sign(t float64) float64 {
+
+	if t > 0 {
+		return 1.0
+	}
+	return -1.0
+
+}
+
+func (l hinge) Loss(y []float64, t []float64) float64 {
+
+	r := 0.0
+	for i := range y {
+		r += math.Max(0, 1-l.sign(t[i])*y[i])
+	}
+	return r / float64(len(y))
+
+}
+
+func (l hinge) Gradient(y []float64, t []float64, g []float64) {
+
+	for i := range y {
+		g[i] = 0.0
+		if l.sign(t[i])*y[i] < 1 {
+			g[i] = -l.sign(t[i]) / float64(len(y))
+		}
+	}
+
+}
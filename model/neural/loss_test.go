@@ -0,0 +1,134 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"math"
+	"testing"
+
+)
+
+// lossCase represents outputs and expected outputs where the gradient of a loss is checked, away from kinks
+type lossCase struct {
+	loss Loss
+	y, t []float64
+}
+
+// #######################################################################################
+
+// finiteGradient returns the central differences of f with respect to each element of x.
+func finiteGradient(f func([]float64) float64, x []float64) []float64 {
+
+	g := make([]float64, len(x))
+	for i := range x {
+		v := x[i]
+		x[i] = v + finiteStep
+		up := f(x)
+		x[i] = v - finiteStep
+		down := f(x)
+		x[i] = v
+		g[i] = (up - down) / (2 * finiteStep)
+	}
+	return g
+
+}
+
+// TestLossGradients checks Gradient of every built-in loss against central differences of Loss.
+func TestLossGradients(t *testing.T) {
+
+	cases := []lossCase{
+		{MeanSquaredError, []float64{0.2, -1.3, 2.5}, []float64{0, 1, 2}},
+		{CrossEntropy, []float64{0.2, 0.7, 0.1}, []float64{0, 1, 0}},
+		{BinaryCrossEntropy, []float64{0.2, 0.7, 0.9}, []float64{0, 1, 1}},
+		// errors below and above the threshold
+		{Huber(DefaultHuberDelta), []float64{0.3, -2.5, 1.8}, []float64{0, 0, 0.1}},
+		{Huber(2.5), []float64{0.3, -4, 1.8}, []float64{0, 0, 0.1}},
+		// margins below and above 1, expected outputs 0 are -1
+		{Hinge, []float64{0.4, -0.3, 1.7, -2}, []float64{1, 0, 1, 0}},
+	}
+
+	for _, c := range cases {
+
+		g := make([]float64, len(c.y))
+		c.loss.Gradient(c.y, c.t, g)
+		want := finiteGradient(func(y []float64) float64 { return c.loss.Loss(y, c.t) }, c.y)
+		for i := range g {
+			if math.Abs(g[i]-want[i]) > finiteTolerance {
+				t.Errorf("%s: derivative %d is %v, central difference %v", c.loss.Name(), i, g[i], want[i])
+			}
+		}
+
+	}
+
+}
+
+// TestFusedLossGradients checks FusedGradient against central differences of Loss of the activation of z.
+func TestFusedLossGradients(t *testing.T) {
+
+	cases := []struct {
+		loss FusedLoss
+		a    Activation
+		z, t []float64
+	}{
+		{CrossEntropy.(FusedLoss), Softmax, []float64{0.5, -1.2, 2}, []float64{0, 0, 1}},
+		{CrossEntropy.(FusedLoss), Sigmoid, []float64{0.5, -1.2, 2}, []float64{0, 1, 1}},
+		{BinaryCrossEntropy.(FusedLoss), Sigmoid, []float64{0.5, -1.2, 2}, []float64{0, 1, 1}},
+	}
+
+	for _, c := range cases {
+
+		y, g := make([]float64, len(c.z)), make([]float64, len(c.z))
+		activate(c.a, c.z, y)
+		if !c.loss.FusedGradient(c.a, c.z, y, c.t, g) {
+			t.Fatalf("%s can not be fused with %s", c.loss.Name(), c.a.Name())
+		}
+
+		want := finiteGradient(func(z []float64) float64 {
+			y := make([]float64, len(z))
+			activate(c.a, z, y)
+			return c.loss.Loss(y, c.t)
+		}, c.z)
+		for i := range g {
+			if math.Abs(g[i]-want[i]) > finiteTolerance {
+				t.Errorf("%s with %s: derivative %d is %v, central difference %v", c.loss.Name(), c.a.Name(), i, g[i], want[i])
+			}
+		}
+
+	}
+
+	// losses are not fused with other activations
+	if CrossEntropy.(FusedLoss).FusedGradient(Tanh, []float64{0}, []float64{0}, []float64{1}, []float64{0}) {
+		t.Error("cross_entropy is fused with tanh")
+	}
+
+}
+
+// TestLossByName checks names of built-in losses, and Huber loss with a custom threshold.
+func TestLossByName(t *testing.T) {
+
+	for _, name := range []string{"mse", "cross_entropy", "binary_cross_entropy", "hinge", "huber", "huber(2.5)"} {
+		l, err := LossByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.Name() != name {
+			t.Errorf("loss %q is named %q", name, l.Name())
+		}
+	}
+
+	l, _ := LossByName("huber(2.5)")
+	if l != Huber(2.5) {
+		t.Errorf("huber(2.5) is %v, want threshold 2.5", l)
+	}
+	if l, _ := LossByName("huber(1)"); l != Huber(DefaultHuberDelta) {
+		t.Errorf("huber(1) is %v, want default threshold", l)
+	}
+
+	for _, name := range []string{"", "huber(", "huber(x)", "l1"} {
+		if _, err := LossByName(name); err == nil {
+			t.Errorf("unknown loss %q has no error", name)
+		}
+	}
+
+}
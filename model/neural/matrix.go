@@ -0,0 +1,125 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+// Dense kernels used by forward and backward passes. Matrices are row-major slices:
+// a matrix with r rows and c columns holds element (i, j) in m[i*c+j].
+
+// dot returns the scalar product of a and b, of the same length.
+func dot(a []float64, b []float64) float64 {
+
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return (s0 + s1) + (s2 + s3)
+
+}
+
+// axpy computes y += alpha * x.
+func axpy(alpha float64, x []float64, y []float64) {
+
+	y = y[:len(x)]
+	for i, v := range x {
+		y[i] += alpha * v
+	}
+
+}
+
+// matVec computes y = W x + b, with W of r rows and c columns (b can be nil).
+func matVec(w []float64, r int, c int, x []float64, b []float64, y []float64) {
+
+	for i := 0; i < r; i++ {
+		y[i] = dot(w[i*c:(i+1)*c], x)
+		if b != nil {
+			y[i] += b[i]
+		}
+	}
+
+}
+
+// matTVec computes y = W^T x, with W of r rows and c columns.
+func matTVec(w []float64, r int, c int, x []float64, y []float64) {
+
+	for j := range y[:c] {
+		y[j] = 0.0
+	}
+	for i := 0; i < r; i++ {
+		if x[i] != 0 {
+			axpy(x[i], w[i*c:(i+1)*c], y)
+		}
+	}
+
+}
+
+// addOuter computes G += alpha * x y^T, with G of len(x) rows and len(y) columns.
+func addOuter(g []float64, alpha float64, x []float64, y []float64) {
+
+	c := len(y)
+	for i, v := range x {
+		if v != 0 {
+			axpy(alpha*v, y, g[i*c:(i+1)*c])
+		}
+	}
+
+}
+
+// matMulT computes C = A W^T + b (b added to each row, can be nil), with A of n rows and k columns,
+// W of m rows and k columns and C of n rows and m columns: it propagates n inputs through a layer at once.
+func matMulT(a []float64, n int, k int, w []float64, m int, b []float64, c []float64) {
+
+	// blocks of rows of W stay in cache while rows of A go through them
+	const block = 64
+	for j0 := 0; j0 < m; j0 += block {
+		j1 := j0 + block
+		if j1 > m {
+			j1 = m
+		}
+		for i := 0; i < n; i++ {
+			ai, ci := a[i*k:(i+1)*k], c[i*m:(i+1)*m]
+			for j := j0; j < j1; j++ {
+				ci[j] = dot(w[j*k:(j+1)*k], ai)
+				if b != nil {
+					ci[j] += b[j]
+				}
+			}
+		}
+	}
+
+}
+
+// matMul computes C = A W, with A of n rows and m columns, W of m rows and k columns
+// and C of n rows and k columns: it propagates back n errors through a layer at once.
+func matMul(a []float64, n int, m int, w []float64, k int, c []float64) {
+
+	for i := 0; i < n; i++ {
+		ci := c[i*k : (i+1)*k]
+		for j := range ci {
+			ci[j] = 0.0
+		}
+		for l, v := range a[i*m : (i+1)*m] {
+			if v != 0 {
+				axpy(v, w[l*k:(l+1)*k], ci)
+			}
+		}
+	}
+
+}
+
+// addMatTMul computes rows lo to hi of G += alpha * D^T X, with D of n rows and m columns, X of n rows and k columns
+// and G of m rows and k columns: it accumulates derivatives of weights of neurons lo to hi of a layer over n patterns,
+// in order of pattern.
+func addMatTMul(g []float64, alpha float64, d []float64, n int, m int, x []float64, k int, lo int, hi int) {
+
+	for i := 0; i < n; i++ {
+		addOuter(g[lo*k:hi*k], alpha, d[i*m+lo:i*m+hi], x[i*k:(i+1)*k])
+	}
+
+}
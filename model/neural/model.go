@@ -0,0 +1,175 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"context"
+	"os"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+	// this repo internal import
+	mu "github.com/made2591/go-perceptron-go/util"
+
+)
+
+// Model represents a trainable classifier, so that validation and other tools work the same way on any of them.
+// Adapters of models of this package are returned by PerceptronModel, MLPModel and ElmanModel.
+type Model interface {
+
+	// Fit trains the model on patterns, stopping cleanly when ctx is done (see InterruptedError)
+	Fit(ctx context.Context, patterns []Pattern) (History, error)
+	// Predict returns the class predicted for features, to compare with SingleExpectation of patterns
+	Predict(features []float64) float64
+	// PredictProba returns the confidence of the model in each class (or output) for features
+	PredictProba(features []float64) []float64
+	// Clone returns an independent copy of the model, that can be trained at the same time as the original one
+	Clone() Model
+
+}
+
+// perceptron adapts a NeuronUnit to Model.
+type perceptron struct {
+	neuron *NeuronUnit
+	epochs int
+	opts   []TrainOptions
+}
+
+// classifier adapts a MultiLayerNetwork trained by MLPTrain to Model.
+type classifier struct {
+	mlp    *MultiLayerNetwork
+	mapped []string
+	epochs int
+	opts   []TrainOptions
+}
+
+// elman adapts an Elman network trained by ElmanTrain to Model.
+type elman struct {
+	mlp    *MultiLayerNetwork
+	epochs int
+	opts   []TrainOptions
+}
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+// PerceptronModel returns a Model that trains neuron with TrainNeuron, resetting its weights, for epochs epochs
+// with optional training settings. It predicts class 0 or 1.
+func PerceptronModel(neuron *NeuronUnit, epochs int, opts ...TrainOptions) Model {
+	return &perceptron{neuron: neuron, epochs: epochs, opts: opts}
+}
+
+// MLPModel returns a Model that trains mlp with MLPTrain on classes mapped, for epochs epochs
+// with optional training settings. It predicts the index of the maximum output.
+func MLPModel(mlp *MultiLayerNetwork, mapped []string, epochs int, opts ...TrainOptions) Model {
+	return &classifier{mlp: mlp, mapped: mapped, epochs: epochs, opts: opts}
+}
+
+// ElmanModel returns a Model that trains the Elman network mlp with ElmanTrain, for epochs epochs
+// with optional training settings. It predicts the integer whose binary digits are the rounded outputs.
+func ElmanModel(mlp *MultiLayerNetwork, epochs int, opts ...TrainOptions) Model {
+	return &elman{mlp: mlp, epochs: epochs, opts: opts}
+}
+
+// cloneTrainOptions returns a copy of training settings for a clone of a model: schedules get their own state
+// and the seed of shuffling is drawn from Rand, if given, so that clones can be trained at the same time.
+// Callbacks are cloned if they can be (as Checkpointer), shared otherwise (see Callback). Clones start a new
+// training, as the checkpoint to resume from is the one of the model cloned.
+func cloneTrainOptions(opts []TrainOptions) []TrainOptions {
+
+	c := make([]TrainOptions, len(opts))
+	for i, o := range opts {
+		o.Schedule = cloneSchedule(o.Schedule)
+		o.Callbacks = cloneCallbacks(o.Callbacks)
+		o.Resume = nil
+		if o.Seed == 0 && o.Rand != nil {
+			o.Seed, o.Rand = o.Rand.Int63(), nil
+		}
+		c[i] = o
+	}
+	return c
+
+}
+
+func (p *perceptron) Fit(ctx context.Context, patterns []Pattern) (History, error) {
+	return TrainNeuronContext(ctx, p.neuron, patterns, p.epochs, 1, p.opts...)
+}
+
+func (p *perceptron) Predict(features []float64) float64 {
+	return Predict(p.neuron, &Pattern{Features: features})
+}
+
+// PredictProba returns 1 for the class predicted, 0 for the other one.
+func (p *perceptron) PredictProba(features []float64) []float64 {
+
+	y := p.Predict(features)
+	return []float64{1 - y, y}
+
+}
+
+func (p *perceptron) Clone() Model {
+	return &perceptron{neuron: p.neuron.Clone(), epochs: p.epochs, opts: cloneTrainOptions(p.opts)}
+}
+
+func (c *classifier) Fit(ctx context.Context, patterns []Pattern) (History, error) {
+	return MLPTrainContext(ctx, c.mlp, patterns, c.mapped, c.epochs, c.opts...)
+}
+
+func (c *classifier) Predict(features []float64) float64 {
+
+	_, i := mu.MaxInSlice(c.mlp.Predict(features))
+	return float64(i)
+
+}
+
+// PredictProba returns outputs of the network normalized to sum 1 (as they are with a Softmax output layer).
+func (c *classifier) PredictProba(features []float64) []float64 {
+
+	y := c.mlp.Predict(features)
+	s := 0.0
+	for _, v := range y {
+		s += v
+	}
+	if s > 0 {
+		for i := range y {
+			y[i] /= s
+		}
+	}
+	return y
+
+}
+
+func (c *classifier) Clone() Model {
+	return &classifier{mlp: c.mlp.Clone(), mapped: c.mapped, epochs: c.epochs, opts: cloneTrainOptions(c.opts)}
+}
+
+func (e *elman) Fit(ctx context.Context, patterns []Pattern) (History, error) {
+	return ElmanTrainContext(ctx, e.mlp, patterns, e.epochs, e.opts...)
+}
+
+func (e *elman) Predict(features []float64) float64 {
+
+	y := e.mlp.Predict(features)
+	for i := range y {
+		y[i] = mu.Round(y[i], .5, 0)
+	}
+	return float64(mu.ConvertBinToInt(y))
+
+}
+
+// PredictProba returns outputs of the network, the confidence of each binary digit being 1.
+func (e *elman) PredictProba(features []float64) []float64 {
+	return e.mlp.Predict(features)
+}
+
+func (e *elman) Clone() Model {
+	return &elman{mlp: e.mlp.Clone(), epochs: e.epochs, opts: cloneTrainOptions(e.opts)}
+}
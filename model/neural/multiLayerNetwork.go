@@ -4,16 +4,18 @@ package neural
 import (
 
 	// sys import
+	"context"
+	"math/rand"
 	"os"
+	"sync"
 	//"fmt"
-	"math"
 
 	// third part import
 	log "github.com/sirupsen/logrus"
+
+	// this repo internal import
 	mu "github.com/made2591/go-perceptron-go/util"
 
-	"time"
-	"math/rand"
 )
 
 func init() {
@@ -28,45 +30,91 @@ type MultiLayerNetwork struct {
 	// Lrate represents learning rate of neuron
 	L_rate float64
 
-	// NeuralLayers represents layer of neurons
+	// NeuralLayers represents layer of neurons, each one with its own transfer function
 	NeuralLayers []NeuralLayer
 
-	// Transfer function
-	T_func transferFunction
+	// Loss represents error function minimized by training (nil is mean squared error). Its gradient 2(y - t) / n,
+	// for n output units, replaced the error t - y of previous versions: with the same learning rate, steps are
+	// 2 / n times those of previous versions.
+	Loss Loss
 
-	// Transfer function derivative
-	T_func_d transferFunction
+	// Optimizer represents algorithm used by training to update weights (nil is plain SGD)
+	Optimizer Optimizer
+
+	// Pipeline represents preprocessing of features, fitted on training patterns by MLPTrain and ElmanTrain
+	// and applied by Predict (nil is none)
+	Pipeline *Pipeline
+
+	// grads, mean represent buffers of derivatives of BackPropagate and of their mean in ApplyGradients,
+	// allocated once
+	grads, mean *Gradients
+	// losses represents buffer of losses of patterns of a batch in ComputeBatchGradients
+	losses []float64
+
+}
+
+// LayerSpec describes a layer of a MultiLayerNetwork.
+type LayerSpec struct {
+
+	// Neurons represents number of NeuronUnit in layer
+	Neurons int
+	// Activation represents transfer function of layer (ignored for the input layer)
+	Activation Activation
+	// WeightInit represents initialization of weights of layer (nil is Normal(SCALING_FACTOR))
+	WeightInit Initializer
+	// BiasInit represents initialization of biases of layer (nil is Normal(SCALING_FACTOR))
+	BiasInit Initializer
 
 }
 
 // PrepareMLPNet create a multi layer Perceptron neural network.
 // [l:[]int] is an int array with layers neurons number [input, ..., output]
 // [lr:int] is the learning rate of neural network
-// [tr:transferFunction] is a transfer function
-// [tr:transferFunction] the respective transfer function derivative
-func PrepareMLPNet(l []int, lr float64, tf transferFunction, trd transferFunction) (mlp MultiLayerNetwork) {
+// [tf:Activation] is a transfer function, with its derivative, used in every layer
+// [rng:rand.Rand] is the source of random weights (nil uses a generator seeded with current time)
+// [init:...Initializer] are optional initializers of weights and biases of every layer
+func PrepareMLPNet(l []int, lr float64, tf Activation, rng *rand.Rand, init ...Initializer) (mlp MultiLayerNetwork) {
+
+	// same transfer function and initialization for each layer
+	wi, bi := initializers(init)
+	ls := make([]LayerSpec, len(l))
+	for il, ql := range l {
+		ls[il] = LayerSpec{Neurons: ql, Activation: tf, WeightInit: wi, BiasInit: bi}
+	}
+
+	return PrepareMLPNetFromSpec(ls, lr, rng)
+
+}
+
+// PrepareMLPNetFromSpec create a multi layer Perceptron neural network with a transfer function for each layer.
+// [ls:[]LayerSpec] is an array with layers specification [input, ..., output]
+// [lr:int] is the learning rate of neural network
+// [rng:rand.Rand] is the source of random weights (nil uses a generator seeded with current time)
+func PrepareMLPNetFromSpec(ls []LayerSpec, lr float64, rng *rand.Rand) (mlp MultiLayerNetwork) {
 
-	// setup learning rate and transfer function
+	// setup learning rate
 	mlp.L_rate = lr
-	mlp.T_func = tf
-	mlp.T_func_d = trd
+
+	// same generator for all layers
+	rng = mu.Rand(rng)
 
 	// setup layers
-	mlp.NeuralLayers = make([]NeuralLayer, len(l))
+	mlp.NeuralLayers = make([]NeuralLayer, len(ls))
 
 	// for each layers specified
-	for il, ql := range l {
+	for il, ql := range ls {
 
 		// if it is not the first
 		if il != 0 {
 
 			// prepare the GENERIC layer with specific dimension and correct number of links for each NeuronUnits
-			mlp.NeuralLayers[il] = PrepareLayer(ql, l[il-1])
+			mlp.NeuralLayers[il] = PrepareLayer(ql.Neurons, ls[il-1].Neurons, rng, ql.WeightInit, ql.BiasInit)
+			mlp.NeuralLayers[il].T_func = ql.Activation
 
 		} else {
 
 			// prepare the INPUT layer with specific dimension and No links to previous.
-			mlp.NeuralLayers[il] = PrepareLayer(ql, 0)
+			mlp.NeuralLayers[il] = PrepareLayer(ql.Neurons, 0, rng, ql.WeightInit, ql.BiasInit)
 
 		}
 
@@ -87,12 +135,12 @@ func PrepareMLPNet(l []int, lr float64, tf transferFunction, trd transferFunctio
 // PrepareElmanNet create a recurrent neUral network neural network.
 // [l:[]int] is an int array with layers neurons number [input, ..., output]
 // [lr:int] is the learning rate of neural network
-// [tr:transferFunction] is a transfer function
-// [tr:transferFunction] the respective transfer function derivative
-func PrepareElmanNet(i int, h int, o int, lr float64, tf transferFunction, trd transferFunction) (rnn MultiLayerNetwork) {
+// [tf:Activation] is a transfer function, with its derivative
+// [rng:rand.Rand] is the source of random weights (nil uses a generator seeded with current time)
+func PrepareElmanNet(i int, h int, o int, lr float64, tf Activation, rng *rand.Rand) (rnn MultiLayerNetwork) {
 
 	// setup a three layer network with Input Context dimension
-	rnn = PrepareMLPNet([]int{i, h, o}, lr, tf, trd);
+	rnn = PrepareMLPNet([]int{i, h, o}, lr, tf, rng);
 
 	log.WithFields(log.Fields{
 		"level":       "info",
@@ -107,332 +155,317 @@ func PrepareElmanNet(i int, h int, o int, lr float64, tf transferFunction, trd t
 
 }
 
-// Execute a multi layer Perceptron neural network.
+// Clone returns an independent copy of the network, with the same weights, transfer functions, loss,
+// optimizer state and pipeline: it can be trained without changing the original one.
+// Transformers that are not built-in need a Clone method; optimizers that are not built-in are copied by their
+// Clone method, if any, otherwise shared.
+func (mlp *MultiLayerNetwork) Clone() *MultiLayerNetwork {
+
+	c := &MultiLayerNetwork{L_rate: mlp.L_rate, Loss: mlp.Loss, Optimizer: cloneOptimizer(mlp.Optimizer), Pipeline: mlp.Pipeline.Clone()}
+	c.NeuralLayers = make([]NeuralLayer, len(mlp.NeuralLayers))
+	for k, l := range mlp.NeuralLayers {
+		c.NeuralLayers[k] = NeuralLayer{NeuronUnits: make([]NeuronUnit, l.Length), Length: l.Length, T_func: l.T_func}
+		for i, n := range l.NeuronUnits {
+			c.NeuralLayers[k].NeuronUnits[i] = NeuronUnit{Weights: append([]float64(nil), n.Weights...), Bias: n.Bias, Lrate: n.Lrate}
+		}
+	}
+	c.pack()
+
+	return c
+
+}
+
+// Execute a multi layer Perceptron neural network, leaving values of last execution in its NeuronUnits:
+// it is not safe for concurrent use, see Predict.
 // [mlp:MultiLayerNetwork] multilayer perceptron network pointer, [s:Pattern] input value
+// [options:...int] 1 to copy output of hidden layer to context units of input layer (Elman network)
 // It returns output values by network
 func Execute(mlp *MultiLayerNetwork, s *Pattern, options ...int) (r []float64) {
 
-	// new value
-	nv := 0.0
+	y := mlp.forward(s.Features, len(options) > 0 && options[0] == 1)
+
+	// NeuronUnits show values of last execution
+	for k := range mlp.NeuralLayers {
+		mlp.NeuralLayers[k].syncValues()
+	}
 
-	// result of execution for each OUTPUT NeuronUnit in OUTPUT NeuralLayer
-	r = make([]float64, mlp.NeuralLayers[len(mlp.NeuralLayers)-1].Length)
+	return append([]float64(nil), y...)
 
-	// show pattern to network =>
-	for i := 0; i < len(s.Features); i++ {
+}
 
-		// setup value of each neurons in first layers to respective features of pattern
-		mlp.NeuralLayers[0].NeuronUnits[i].Value = s.Features[i]
+// Predict executes the network on features without changing it, keeping values of units in pooled buffers:
+// a trained network can serve concurrent predictions, as long as it is not trained at the same time.
+// Features are preprocessed by the pipeline of the network, if any; context units of recurrent networks are set to 0.5.
+// It returns output values by network
+func (mlp *MultiLayerNetwork) Predict(features []float64) []float64 {
 
+	if mlp.Pipeline != nil {
+		features = mlp.Pipeline.Transform(features)
 	}
 
-	// init context
-	for i := len(s.Features); i < mlp.NeuralLayers[0].Length; i++ {
+	// largest layer
+	m := 0
+	for k := range mlp.NeuralLayers {
+		if mlp.NeuralLayers[k].Length > m {
+			m = mlp.NeuralLayers[k].Length
+		}
+	}
 
-		// setup value of each neurons in context layers to 0.5
-		mlp.NeuralLayers[0].NeuronUnits[i].Value = 0.5
+	sc := predictPool.Get().(*predictScratch)
+	defer predictPool.Put(sc)
+	if cap(sc.x) < m {
+		sc.x, sc.z, sc.y = make([]float64, m), make([]float64, m), make([]float64, m)
+	}
 
+	// show features to network, then init context
+	in := mlp.NeuralLayers[0].Length
+	x := sc.x[:in]
+	n := copy(x, features)
+	for i := n; i < in; i++ {
+		x[i] = 0.5
 	}
 
-	//OLD: TODO REMOVE
-	// show pattern to network =>
-	//for i := 0; i < mlp.NeuralLayers[0].Length; i++ {
-	//
-	//	// setup value of each neurons in first layers to respective features of pattern
-	//	mlp.NeuralLayers[0].NeuronUnits[i].Value = s.Features[i]
-	//
-	//}
-	//OLD: END REMOVE
-
-	// execute - hiddens + output
-	// for each layers from first hidden to output
+	// for each layers from first hidden to output, reading weights and biases of NeuronUnits
 	for k := 1; k < len(mlp.NeuralLayers); k++ {
 
-		// for each neurons in focused level
-		for i := 0; i < mlp.NeuralLayers[k].Length; i++ {
-
-			// init new value
-			nv = 0.0
-
-			// for each neurons in previous level (for k = 1, INPUT)
-			for j := 0; j < mlp.NeuralLayers[k - 1].Length; j++ {
-
-				// sum output value of previous neurons multiplied by weight between previous and focused neuron
-				nv += mlp.NeuralLayers[k].NeuronUnits[i].Weights[j] * mlp.NeuralLayers[k - 1].NeuronUnits[j].Value
-
-				log.WithFields(log.Fields{
-					"level":     "debug",
-					"msg":       "multilayer perceptron execution",
-					"len(mlp.NeuralLayers)":  len(mlp.NeuralLayers),
-					"layer:  ": k,
-					"neuron: ": i,
-					"previous neuron: ": j,
-				}).Debug("Compute output propagation.")
+		l := &mlp.NeuralLayers[k]
+		z, y := sc.z[:l.Length], sc.y[:l.Length]
+		for i := range l.NeuronUnits {
+			z[i] = dot(l.NeuronUnits[i].Weights, x) + l.NeuronUnits[i].Bias
+		}
+		activate(l.T_func, z, y)
 
-			}
+		// output becomes input of next layer
+		x = y
+		sc.x, sc.y = sc.y, sc.x
 
-			// add neuron bias
-			nv += mlp.NeuralLayers[k].NeuronUnits[i].Bias
+	}
 
-			// compute activation function to new output value
-			mlp.NeuralLayers[k].NeuronUnits[i].Value = mlp.T_func(nv)
+	return append([]float64(nil), x...)
 
-			// save output of hidden layer to context if nextwork is RECURRENT
-			if k == 1 && len(options) > 0 && options[0] == 1 {
+}
 
-				for z := len(s.Features); z < mlp.NeuralLayers[0].Length; z++ {
+// predictScratch represents buffers used by a prediction, for input and outputs of a layer.
+type predictScratch struct {
+	x, z, y []float64
+}
 
-					log.WithFields(log.Fields{
-						"level"				: "debug",
-						"len z" 			: z,
-						"s.Features"		: s.Features,
-						"len(s.Features)" : len(s.Features),
-						"len mlp.NeuralLayers[0].NeuronUnits" : len(mlp.NeuralLayers[0].NeuronUnits),
-						"len mlp.NeuralLayers[k].NeuronUnits" : len(mlp.NeuralLayers[k].NeuronUnits),
-					}).Debug("Save output of hidden layer to context.")
+// predictPool keeps buffers of predictions, shared by all networks.
+var predictPool = sync.Pool{New: func() interface{} { return &predictScratch{} }}
 
-					mlp.NeuralLayers[0].NeuronUnits[z].Value = mlp.NeuralLayers[k].NeuronUnits[z-len(s.Features)].Value
+// pack makes sure that weights of each layer are stored in its contiguous matrix, see NeuralLayer.
+func (mlp *MultiLayerNetwork) pack() {
 
-				}
+	for k := range mlp.NeuralLayers {
+		p := 0
+		if k > 0 {
+			p = mlp.NeuralLayers[k-1].Length
+		}
+		mlp.NeuralLayers[k].pack(p)
+	}
 
-			}
+}
 
-			log.WithFields(log.Fields{
-				"level":     "debug",
-				"msg":       "setup new neuron output value after transfer function application",
-				"len(mlp.NeuralLayers)":  len(mlp.NeuralLayers),
-				"layer:  ": k,
-				"neuron: ": i,
-				"outputvalue" : mlp.NeuralLayers[k].NeuronUnits[i].Value,
-			}).Debug("Setup new neuron output value after transfer function application.")
+// forward propagates input x through the network, leaving weighted inputs and outputs of each layer in its buffers.
+// Input units beyond x are context units, set to 0.5 and, if recurrent, to outputs of first hidden layer
+// once computed. It returns outputs of the network, valid until next propagation.
+func (mlp *MultiLayerNetwork) forward(x []float64, recurrent bool) []float64 {
 
-		}
+	mlp.pack()
 
+	// show pattern to network, then init context
+	in := &mlp.NeuralLayers[0]
+	n := copy(in.a, x)
+	for i := n; i < in.Length; i++ {
+		in.a[i] = 0.5
 	}
+	copy(in.z, in.a)
 
+	// for each layers from first hidden to output: weighted inputs, then transfer function
+	for k := 1; k < len(mlp.NeuralLayers); k++ {
 
-	// get ouput values
-	for i := 0; i < mlp.NeuralLayers[len(mlp.NeuralLayers)-1].Length; i++ {
+		l, prev := &mlp.NeuralLayers[k], &mlp.NeuralLayers[k-1]
+		matVec(l.W, l.Length, prev.Length, prev.a, l.B, l.z)
+		activate(l.T_func, l.z, l.a)
 
-		// simply accumulate values of all neurons in last level
-		r[i] = mlp.NeuralLayers[len(mlp.NeuralLayers)-1].NeuronUnits[i].Value
+		// save output of hidden layer to context if network is RECURRENT
+		if k == 1 && recurrent {
+			copy(in.a[n:], l.a)
+		}
 
 	}
 
-	return r
+	return mlp.NeuralLayers[len(mlp.NeuralLayers)-1].a
 
 }
 
+// backward propagates back the error between outputs y of last forward propagation and expected outputs t,
+// leaving deltas of each layer (opposite of derivative of the loss with respect to weighted inputs) in its buffers.
+func (mlp *MultiLayerNetwork) backward(y []float64, t []float64) {
 
+	ol := &mlp.NeuralLayers[len(mlp.NeuralLayers)-1]
+	outputDeltas(mlp.lossFunc(), ol.T_func, ol.z, y, t, ol.e, ol.d)
 
-// BackPropagation algorithm for assisted learning. Convergence is not guaranteed and very slow.
-// Use as a stop criterion the average between previous and current errors and a maximum number of iterations.
-// [mlp:MultiLayerNetwork] input value		[s:Pattern] input value (scaled between 0 and 1)
-// [o:[]float64] expected output value (scaled between 0 and 1)
-// return [r:float64] delta error between generated output and expected output
-func BackPropagate(mlp *MultiLayerNetwork, s *Pattern, o []float64, options ...int) (r float64) {
-
-	var no []float64;
-	// execute network with pattern passed over each level to output
-	if len(options) == 1 {
-		no = Execute(mlp, s, options[0])
-	} else {
-		no = Execute(mlp, s)
+	// for each layers starting from the last hidden: error is the sum of deltas of next layer
+	// weighted by links, delta is error * derivative of transfer function
+	for k := len(mlp.NeuralLayers) - 2; k >= 1; k-- {
+		l, next := &mlp.NeuralLayers[k], &mlp.NeuralLayers[k+1]
+		matTVec(next.W, next.Length, l.Length, next.d, l.e)
+		derive(l.T_func, l.z, l.a, l.e, l.d)
 	}
 
-	// init error
-	e := 0.0
-
-	// compute output error and delta in output layer
-	for i := 0; i < mlp.NeuralLayers[len(mlp.NeuralLayers)-1].Length; i++ {
-
-		// compute error in output: output for given pattern - output computed by network
-		e = o[i] - no[i]
+}
 
-		// compute delta for each neuron in output layer as:
-		// error in output * derivative of transfer function of network output
-		mlp.NeuralLayers[len(mlp.NeuralLayers)-1].NeuronUnits[i].Delta = e * mlp.T_func_d(no[i])
+// outputDeltas computes deltas d of output units, with weighted inputs z, outputs y and transfer function a,
+// as opposite of the derivative of loss with respect to weighted inputs given expected outputs t:
+// directly if loss can be fused with transfer function, otherwise as error in output e * derivative of a.
+func outputDeltas(loss Loss, a Activation, z []float64, y []float64, t []float64, e []float64, d []float64) {
 
+	if fl, ok := loss.(FusedLoss); ok && a != nil && fl.FusedGradient(a, z, y, t, e) {
+		for i := range e {
+			d[i] = -e[i]
+		}
+		return
 	}
 
-	// backpropagate error to previous layers
-	// for each layers starting from the last hidden (len(mlp.NeuralLayers)-2)
-	for k := len(mlp.NeuralLayers)-2; k >= 0; k-- {
-
-		// compute actual layer errors and re-compute delta
-		for i := 0; i < mlp.NeuralLayers[k].Length; i++ {
+	// error in output: opposite of loss derivative with respect to output computed by network
+	loss.Gradient(y, t, e)
+	for i := range e {
+		e[i] = -e[i]
+	}
+	derive(a, z, y, e, d)
 
-			// reset error accumulator
-			e = 0.0
+}
 
-			// for each link to next layer
-			for j := 0; j < mlp.NeuralLayers[k + 1].Length; j++ {
+// BackPropagation algorithm for assisted learning. Convergence is not guaranteed and very slow.
+// Use as a stop criterion the average between previous and current errors and a maximum number of iterations.
+// [mlp:MultiLayerNetwork] input value		[s:Pattern] input value (scaled between 0 and 1, see MinMaxScaler)
+// [o:[]float64] expected output value (scaled between 0 and 1)
+// return [r:float64] error between generated output and expected output, computed by loss function of network
+func BackPropagate(mlp *MultiLayerNetwork, s *Pattern, o []float64, options ...int) (r float64) {
 
-				// sum delta value of next neurons multiplied by weight between focused neuron and all neurons in next level
-				e += mlp.NeuralLayers[k + 1].NeuronUnits[j].Delta * mlp.NeuralLayers[k + 1].NeuronUnits[j].Weights[i]
+	// compute derivatives for pattern and update weights
+	mlp.grads = reuse(mlp.grads, mlp)
+	r = ComputeGradients(mlp, s, o, mlp.grads, options...)
+	ApplyGradients(mlp, mlp.grads, mlp.L_rate)
 
-			}
+	return
 
-			// compute delta for each neuron in focused layer as error * derivative of transfer function
-			mlp.NeuralLayers[k].NeuronUnits[i].Delta = e * mlp.T_func_d(mlp.NeuralLayers[k].NeuronUnits[i].Value)
+}
 
+// ComputeGradients executes the network on pattern and propagates back the error,
+// accumulating derivatives of the loss in g without updating weights.
+// [mlp:MultiLayerNetwork] input value		[s:Pattern] input value
+// [o:[]float64] expected output value		[g:Gradients] accumulator of derivatives
+// return [r:float64] error between generated output and expected output, computed by loss function of network
+func ComputeGradients(mlp *MultiLayerNetwork, s *Pattern, o []float64, g *Gradients, options ...int) (r float64) {
+
+	// execute network with pattern passed over each level to output, then propagate back the error
+	no := mlp.forward(s.Features, len(options) > 0 && options[0] == 1)
+	mlp.backward(no, o)
+
+	// NeuronUnits show values and deltas of last pattern
+	for k := range mlp.NeuralLayers {
+		mlp.NeuralLayers[k].syncValues()
+		if k > 0 {
+			mlp.NeuralLayers[k].syncDeltas()
 		}
+	}
 
-		// compute weights in the next layer
-		// for each link to next layer
-		for i := 0; i < mlp.NeuralLayers[k + 1].Length; i++ {
-
-			// for each neurons in actual level (for k = 0, INPUT)
-			for j := 0; j < mlp.NeuralLayers[k].Length; j++ {
-
-				// sum learning rate * next level next neuron Delta * actual level actual neuron output value
-				mlp.NeuralLayers[k + 1].NeuronUnits[i].Weights[j] +=
-					mlp.L_rate * mlp.NeuralLayers[k + 1].NeuronUnits[i].Delta * mlp.NeuralLayers[k].NeuronUnits[j].Value
-
-			}
+	// compute global error with loss function of network
+	r = mlp.lossFunc().Loss(no, o)
 
-			// learning rate * next level next neuron Delta * actual level actual neuron output value
-			mlp.NeuralLayers[k + 1].NeuronUnits[i].Bias += mlp.L_rate * mlp.NeuralLayers[k + 1].NeuronUnits[i].Delta
+	// accumulate derivatives of weights
+	g.accumulate(mlp, r)
 
-		}
+	return
 
-		// copy hidden output to context
-		if k == 1 && len(options) > 0 && options[0] == 1 {
+}
 
-			for z := len(s.Features); z < mlp.NeuralLayers[0].Length; z++ {
+// lossFunc returns loss function of network, mean squared error if not specified.
+func (mlp *MultiLayerNetwork) lossFunc() Loss {
 
-				// save output of hidden layer to context
-				mlp.NeuralLayers[0].NeuronUnits[z].Value = mlp.NeuralLayers[k].NeuronUnits[z-len(s.Features)].Value
+	if mlp.Loss == nil {
+		return MeanSquaredError
+	}
+	return mlp.Loss
 
-			}
+}
 
-		}
+// MLPTrain train a mlp MultiLayerNetwork with BackPropagation algorithm for assisted learning, for at most epochs epochs.
+// Derivatives are accumulated over batches of patterns, learning rate changes and training stops early, as specified
+// by optional training settings (online training with constant learning rate for all epochs if not specified).
+// It returns the history of training.
+func MLPTrain(mlp *MultiLayerNetwork, patterns []Pattern, mapped []string, epochs int, opts ...TrainOptions) History {
 
-	}
+	h, err := MLPTrainContext(context.Background(), mlp, patterns, mapped, epochs, opts...)
+	logTrainError("MLPTrain", err)
+	return h
 
-	// compute global errors as sum of abs difference between output execution for each neuron in output layer
-	// and desired value in each neuron in output layer
-	for i := 0; i < len(o); i++ {
+}
 
-		r += math.Abs(no[i] - o[i])
+// MLPTrainContext is like MLPTrain but stops cleanly after a batch when ctx is done:
+// in that case mlp keeps the weights reached and an InterruptedError is returned with the history so far.
+func MLPTrainContext(ctx context.Context, mlp *MultiLayerNetwork, patterns []Pattern, mapped []string, epochs int, opts ...TrainOptions) (History, error) {
 
+	opt := trainOptions(opts)
+	train, validation, err := opt.readers(patterns, mlp.Pipeline)
+	if err != nil {
+		return History{}, err
 	}
-
-	// average error
-	r = r / float64(len(o))
-
-	return
+	return trainNetwork(ctx, mlp, train, validation, epochs, opt, classifierTask(len(mapped)))
 
 }
 
-// MLPTrain train a mlp MultiLayerNetwork with BackPropagation algorithm for assisted learning.
-func MLPTrain(mlp *MultiLayerNetwork, patterns []Pattern, mapped []string, epochs int) {
+// MLPTrainSource is like MLPTrain, reading training patterns from src in each epoch instead of keeping them
+// in memory: batches are read in the order of src (use Shuffled to shuffle them), and only patterns given
+// as TrainOptions.Validation are used for validation. Resuming from a checkpoint reads src again from the first
+// pattern, with the state it had at the end of the epoch (see RestorableSource).
+// The pipeline of mlp, if any, is applied to patterns but not fitted: fit it before, on a sample of patterns.
+func MLPTrainSource(mlp *MultiLayerNetwork, src PatternSource, mapped []string, epochs int, opts ...TrainOptions) History {
 
-	epoch := 0
-	output := make([]float64, len(mapped))
+	h, err := MLPTrainSourceContext(context.Background(), mlp, src, mapped, epochs, opts...)
+	logTrainError("MLPTrain", err)
+	return h
 
-	// for fixed number of epochs
-	for {
+}
 
-		// for each pattern in training set
-		for _, pattern := range patterns {
+// MLPTrainSourceContext is like MLPTrainSource but stops cleanly after a batch when ctx is done, as MLPTrainContext.
+// It also returns the error of src, if reading it fails.
+func MLPTrainSourceContext(ctx context.Context, mlp *MultiLayerNetwork, src PatternSource, mapped []string, epochs int, opts ...TrainOptions) (History, error) {
 
-			// setup desired output for each unit
-			for io, _ := range output {
-				output[io] = 0.0
-			}
-			// setup desired output for specific class of pattern focused
-			output[int(pattern.SingleExpectation)] = 1.0
-			// back propagation
-			BackPropagate(mlp, &pattern, output)
+	opt := trainOptions(opts)
+	validation := opt.Validation
+	if mlp.Pipeline != nil {
+		src, validation = &transformedSource{PatternSource: src, p: mlp.Pipeline}, mlp.Pipeline.TransformPatterns(validation)
+	}
+	return trainNetwork(ctx, mlp, opt.sourceReader(src), validation, epochs, opt, classifierTask(len(mapped)))
 
-		}
+}
 
-		log.WithFields(log.Fields{
-			"level":             "info",
-			"place":             "validation",
-			"method":            "MLPTrain",
-			"epoch":        	 epoch,
-		}).Debug("Training epoch completed.")
-
-		// if max number of epochs is reached
-		if epoch > epochs {
-			// exit
-			break
-		}
-		// increase number of epoch
-		epoch++
+// ElmanTrain train a mlp MultiLayerNetwork with BackPropagation algorithm for assisted learning, for at most epochs epochs.
+// Patterns are shown in order, one at a time; learning rate changes and training stops early as specified
+// by optional training settings. It returns the history of training.
+func ElmanTrain(mlp *MultiLayerNetwork, patterns []Pattern, epochs int, opts ...TrainOptions) History {
 
-	}
+	h, err := ElmanTrainContext(context.Background(), mlp, patterns, epochs, opts...)
+	logTrainError("ElmanTrain", err)
+	return h
 
 }
 
-// ElmanTrain train a mlp MultiLayerNetwork with BackPropagation algorithm for assisted learning.
-func ElmanTrain(mlp *MultiLayerNetwork, patterns []Pattern, epochs int) {
-
-	epoch := 0
-
-	// for fixed number of epochs
-	for {
-
-		rand.Seed(time.Now().UTC().UnixNano())
-		p_i_r := rand.Intn(len(patterns))
-
-		// for each pattern in training set
-		for p_i, pattern := range patterns {
-
-			// back propagation
-			BackPropagate(mlp, &pattern, pattern.MultipleExpectation, 1)
-
-			if (epoch % 100 == 0 && p_i == p_i_r) {
-
-				// get output from network
-				o_out := Execute(mlp, &pattern, 1)
-				for o_out_i, o_out_v := range(o_out) {
-					o_out[o_out_i] = mu.Round(o_out_v, .5, 0)
-				}
-				log.WithFields(log.Fields{
-					"SUM":	"  ==========================",
-				}).Info()
-				log.WithFields(log.Fields{
-					"a_n_1":	mu.ConvertBinToInt(pattern.Features[0:int(len(pattern.Features)/2)]),
-					"a_n_2":	pattern.Features[0:int(len(pattern.Features)/2)],
-				}).Info()
-				log.WithFields(log.Fields{
-					"b_n_1":	mu.ConvertBinToInt(pattern.Features[int(len(pattern.Features)/2):]),
-					"b_n_2":	pattern.Features[int(len(pattern.Features)/2):],
-				}).Info()
-				log.WithFields(log.Fields{
-					"sum_1":	mu.ConvertBinToInt(pattern.MultipleExpectation),
-					"sum_2":	pattern.MultipleExpectation,
-				}).Info()
-				log.WithFields(log.Fields{
-					"sum_1":	mu.ConvertBinToInt(o_out),
-					"sum_2":	o_out,
-				}).Info()
-				log.WithFields(log.Fields{
-					"END":	"  ==========================",
-				}).Info()
-
-			}
+// ElmanTrainContext is like ElmanTrain but stops cleanly after a pattern when ctx is done:
+// in that case mlp keeps the weights reached and an InterruptedError is returned with the history so far.
+func ElmanTrainContext(ctx context.Context, mlp *MultiLayerNetwork, patterns []Pattern, epochs int, opts ...TrainOptions) (History, error) {
 
-		}
-
-		log.WithFields(log.Fields{
-			"level":             "info",
-			"place":             "validation",
-			"method":            "ElmanTrain",
-			"epoch":        	 epoch,
-		}).Debug("Training epoch completed.")
-
-		// if max number of epochs is reached
-		if epoch > epochs {
-			// exit
-			break
-		}
-		// increase number of epoch
-		epoch++
+	// sequences are learned online and in order
+	opt := trainOptions(opts)
+	opt.BatchSize, opt.Shuffle = 0, false
 
+	train, validation, err := opt.readers(patterns, mlp.Pipeline)
+	if err != nil {
+		return History{}, err
 	}
+	return trainNetwork(ctx, mlp, train, validation, epochs, opt, sequenceTask())
 
-}
\ No newline at end of file
+}
@@ -0,0 +1,210 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+
+)
+
+// sonarPath represents the sonar data set, from the directory of the package
+const sonarPath = "../../res/sonar.all_data.csv"
+
+// #######################################################################################
+
+// sonarTargets returns expected outputs of patterns of classes mapped, as MLPTrain does.
+func sonarTargets(patterns []Pattern, mapped []string) [][]float64 {
+
+	ts := make([][]float64, len(patterns))
+	for i := range patterns {
+		ts[i] = make([]float64, len(mapped))
+		ts[i][int(patterns[i].SingleExpectation)] = 1
+	}
+	return ts
+
+}
+
+// perNeuronBackPropagate is BackPropagate as it was before weights were packed in matrices, walking NeuronUnits
+// one scalar at a time: mean squared error, plain SGD. It is the reference of BenchmarkMLPTrainSonar.
+func perNeuronBackPropagate(mlp *MultiLayerNetwork, s *Pattern, o []float64) {
+
+	// show pattern to network
+	for i := range s.Features {
+		mlp.NeuralLayers[0].NeuronUnits[i].Value = s.Features[i]
+	}
+
+	// forward, neuron by neuron
+	for k := 1; k < len(mlp.NeuralLayers); k++ {
+		l, prev := &mlp.NeuralLayers[k], &mlp.NeuralLayers[k-1]
+		for i := range l.NeuronUnits {
+			nv := 0.0
+			for j := range prev.NeuronUnits {
+				nv += l.NeuronUnits[i].Weights[j] * prev.NeuronUnits[j].Value
+			}
+			l.NeuronUnits[i].Net = nv + l.NeuronUnits[i].Bias
+			l.NeuronUnits[i].Value = l.T_func.Forward(l.NeuronUnits[i].Net)
+		}
+	}
+
+	// deltas of output layer, then of previous layers
+	ol := &mlp.NeuralLayers[len(mlp.NeuralLayers)-1]
+	for i := range ol.NeuronUnits {
+		u := &ol.NeuronUnits[i]
+		u.Delta = -2 * (u.Value - o[i]) / float64(ol.Length) * ol.T_func.Derivative(u.Net, u.Value)
+	}
+	for k := len(mlp.NeuralLayers) - 2; k >= 1; k-- {
+		l, next := &mlp.NeuralLayers[k], &mlp.NeuralLayers[k+1]
+		for j := range l.NeuronUnits {
+			e := 0.0
+			for i := range next.NeuronUnits {
+				e += next.NeuronUnits[i].Weights[j] * next.NeuronUnits[i].Delta
+			}
+			l.NeuronUnits[j].Delta = e * l.T_func.Derivative(l.NeuronUnits[j].Net, l.NeuronUnits[j].Value)
+		}
+	}
+
+	// update weights and biases, neuron by neuron
+	for k := 1; k < len(mlp.NeuralLayers); k++ {
+		l, prev := &mlp.NeuralLayers[k], &mlp.NeuralLayers[k-1]
+		for i := range l.NeuronUnits {
+			u := &l.NeuronUnits[i]
+			for j := range prev.NeuronUnits {
+				u.Weights[j] += mlp.L_rate * u.Delta * prev.NeuronUnits[j].Value
+			}
+			u.Bias += mlp.L_rate * u.Delta
+		}
+	}
+
+}
+
+// TestPerNeuronReference checks that the reference of BenchmarkMLPTrainSonar trains as BackPropagate.
+func TestPerNeuronReference(t *testing.T) {
+
+	patterns, err, mapped := LoadPatternsFromCSVFile(sonarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := sonarTargets(patterns, mapped)
+
+	packed := PrepareMLPNet([]int{60, 20, 20, 2}, 0.1, Sigmoid, rand.New(rand.NewSource(1)))
+	reference := packed.Clone()
+	for i := 0; i < 10; i++ {
+		BackPropagate(&packed, &patterns[i], ts[i])
+		perNeuronBackPropagate(reference, &patterns[i], ts[i])
+	}
+
+	for k := 1; k < len(packed.NeuralLayers); k++ {
+		for i, u := range reference.NeuralLayers[k].NeuronUnits {
+			if d := math.Abs(u.Bias - packed.NeuralLayers[k].B[i]); d > 1e-12 {
+				t.Errorf("bias %d of layer %d differs by %v", i, k, d)
+			}
+			for j, w := range u.Weights {
+				if d := math.Abs(w - packed.NeuralLayers[k].NeuronUnits[i].Weights[j]); d > 1e-12 {
+					t.Fatalf("weight %d of neuron %d of layer %d differs by %v", j, i, k, d)
+				}
+			}
+		}
+	}
+
+}
+
+// BenchmarkMLPTrainSonar measures an epoch of online training on the sonar data set, with weights packed in
+// matrices (BackPropagate) and walking NeuronUnits one scalar at a time (the way it was before).
+func BenchmarkMLPTrainSonar(b *testing.B) {
+
+	patterns, err, mapped := LoadPatternsFromCSVFile(sonarPath)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ts := sonarTargets(patterns, mapped)
+
+	paths := []struct {
+		name  string
+		train func(mlp *MultiLayerNetwork, s *Pattern, o []float64)
+	}{
+		{"packed", func(mlp *MultiLayerNetwork, s *Pattern, o []float64) { BackPropagate(mlp, s, o) }},
+		{"per-neuron", perNeuronBackPropagate},
+	}
+
+	for _, hidden := range []int{30, 100} {
+		for _, path := range paths {
+			b.Run(fmt.Sprintf("%s/hidden=%d", path.name, hidden), func(b *testing.B) {
+
+				mlp := PrepareMLPNet([]int{60, hidden, hidden, 2}, 0.1, Sigmoid, rand.New(rand.NewSource(1)))
+				b.ResetTimer()
+				for n := 0; n < b.N; n++ {
+					for i := range patterns {
+						path.train(&mlp, &patterns[i], ts[i])
+					}
+				}
+
+			})
+		}
+	}
+
+}
+
+// TestPredictConcurrent checks that predictions of goroutines sharing networks are the ones of a serial run.
+// Networks of different sizes share buffers of predictions: run it with -race.
+func TestPredictConcurrent(t *testing.T) {
+
+	patterns, err, _ := LoadPatternsFromCSVFile(sonarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	mlps := []MultiLayerNetwork{
+		PrepareMLPNet([]int{60, 30, 2}, 0.1, Sigmoid, rng),
+		PrepareMLPNet([]int{60, 100, 100, 2}, 0.1, Sigmoid, rng),
+	}
+
+	// serial run
+	want := make([][][]float64, len(mlps))
+	for m := range mlps {
+		want[m] = make([][]float64, len(patterns))
+		for i := range patterns {
+			want[m][i] = mlps[m].Predict(patterns[i].Features)
+		}
+	}
+
+	// each goroutine predicts all patterns with both networks, from a different pattern
+	const goroutines = 16
+	got := make([][][][]float64, goroutines)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+
+			defer wg.Done()
+			got[g] = [][][]float64{make([][]float64, len(patterns)), make([][]float64, len(patterns))}
+			for n := range patterns {
+				i := (n + g*len(patterns)/goroutines) % len(patterns)
+				for m := range mlps {
+					got[g][m][i] = mlps[m].Predict(patterns[i].Features)
+				}
+			}
+
+		}(g)
+	}
+	wg.Wait()
+
+	for g := range got {
+		for m := range mlps {
+			for i := range patterns {
+				for j := range want[m][i] {
+					if got[g][m][i][j] != want[m][i][j] {
+						t.Fatalf("goroutine %d, network %d, pattern %d: output %d is %v, serially %v",
+							g, m, i, j, got[g][m][i][j], want[m][i][j])
+					}
+				}
+			}
+		}
+	}
+
+}
@@ -4,11 +4,15 @@ package neural
 import (
 
 	// sys import
+	"math/rand"
 	"os"
 
 	// third part import
 	log "github.com/sirupsen/logrus"
 
+	// this repo internal import
+	mu "github.com/made2591/go-perceptron-go/util"
+
 )
 
 // Level struct represents a simple NeuronUnits network with a slice of n NeuronUnits.
@@ -18,6 +22,19 @@ type NeuralLayer struct {
 	NeuronUnits []NeuronUnit
 	// Lrate represents number of NeuronUnit in layer
 	Length int
+	// T_func represents transfer function of layer, with its derivative (nil is identity)
+	T_func Activation
+
+	// W represents weights of layer as a contiguous matrix, one row for each NeuronUnit:
+	// Weights of each NeuronUnit are a view of its row
+	W []float64
+	// B represents biases of layer, kept in sync with Bias of each NeuronUnit
+	B []float64
+
+	// z, a, e, d represent weighted inputs, outputs, errors and deltas of units for last pattern
+	z, a, e, d []float64
+	// zb, ab, eb, db represent the same for last batch of patterns, one row for each pattern
+	zb, ab, eb, db []float64
 
 }
 
@@ -35,13 +52,27 @@ func init() {
 // PrepareLayer create a NeuralLayer with n NeuronUnits inside
 // [n:int] is an int that specifies the number of neurons in the NeuralLayer
 // [p:int] is an int that specifies the number of neurons in the previous NeuralLayer
+// [rng:rand.Rand] is the source of random weights (nil uses a generator seeded with current time)
+// [init:...Initializer] are optional initializers of weights and biases (nil or missing is RandomNeuronInit)
 // It returns a NeuralLayer object
-func PrepareLayer(n int, p int) (l NeuralLayer) {
+func PrepareLayer(n int, p int, rng *rand.Rand, init ...Initializer) (l NeuralLayer) {
 
 	l = NeuralLayer{NeuronUnits: make([]NeuronUnit, n), Length: n}
 
-	for i := 0; i < n; i++ {
-		RandomNeuronInit(&l.NeuronUnits[i], p)
+	rng = mu.Rand(rng)
+	wi, bi := initializers(init)
+	if wi == nil && bi == nil {
+		for i := 0; i < n; i++ {
+			RandomNeuronInit(&l.NeuronUnits[i], p, rng)
+		}
+	} else {
+		if wi == nil {
+			wi = Normal(SCALING_FACTOR)
+		}
+		if bi == nil {
+			bi = Normal(SCALING_FACTOR)
+		}
+		initLayer(&l, p, wi, bi, rng)
 	}
 
 	log.WithFields(log.Fields{
@@ -55,4 +86,120 @@ func PrepareLayer(n int, p int) (l NeuralLayer) {
 
 }
 
-//make([]NeuronUnit, n)
\ No newline at end of file
+// activate computes outputs y of units with weighted inputs z and transfer function a (nil is identity).
+func activate(a Activation, z []float64, y []float64) {
+
+	if a == nil {
+		a = Linear
+	}
+
+	// activation depending on all the layer
+	if va, ok := a.(VectorActivation); ok {
+		va.ForwardVector(z, y)
+		return
+	}
+
+	for i := range z {
+		y[i] = a.Forward(z[i])
+	}
+
+}
+
+// derive computes deltas d of units with weighted inputs z, outputs y and transfer function a (nil is identity),
+// given their errors e.
+func derive(a Activation, z []float64, y []float64, e []float64, d []float64) {
+
+	if a == nil {
+		a = Linear
+	}
+
+	// activation depending on all the layer
+	if va, ok := a.(VectorActivation); ok {
+		va.BackwardVector(z, y, e, d)
+		return
+	}
+
+	for i := range z {
+		d[i] = e[i] * a.Derivative(z[i], y[i])
+	}
+
+}
+
+// pack makes sure that weights of layer, with p inputs for each unit, are stored in W with NeuronUnits viewing
+// its rows (moving them if they were replaced), copies Bias of each NeuronUnit in B and allocates buffers.
+// It costs O(Length) if weights are already packed.
+func (l *NeuralLayer) pack(p int) {
+
+	n := l.Length
+
+	if len(l.W) != n*p || !l.packed(p) {
+		w := make([]float64, n*p)
+		for i := range l.NeuronUnits {
+			copy(w[i*p:(i+1)*p], l.NeuronUnits[i].Weights)
+			l.NeuronUnits[i].Weights = w[i*p : (i+1)*p : (i+1)*p]
+		}
+		l.W = w
+	}
+
+	if len(l.B) != n {
+		l.B = make([]float64, n)
+	}
+	for i := range l.NeuronUnits {
+		l.B[i] = l.NeuronUnits[i].Bias
+	}
+
+	if len(l.a) != n {
+		l.z, l.a, l.e, l.d = make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	}
+
+}
+
+// packed returns true if Weights of each NeuronUnit are a view of its row of W.
+func (l *NeuralLayer) packed(p int) bool {
+
+	for i := range l.NeuronUnits {
+		v := l.NeuronUnits[i].Weights
+		if len(v) != p || (p > 0 && &v[0] != &l.W[i*p]) {
+			return false
+		}
+	}
+	return true
+
+}
+
+// batch allocates buffers for a batch of n patterns.
+func (l *NeuralLayer) batch(n int) {
+
+	if len(l.ab) != n*l.Length {
+		m := n * l.Length
+		l.zb, l.ab, l.eb, l.db = make([]float64, m), make([]float64, m), make([]float64, m), make([]float64, m)
+	}
+
+}
+
+// syncBiases copies B back to Bias of each NeuronUnit.
+func (l *NeuralLayer) syncBiases() {
+
+	for i := range l.NeuronUnits {
+		l.NeuronUnits[i].Bias = l.B[i]
+	}
+
+}
+
+// syncValues copies weighted inputs and outputs of last pattern to Net and Value of each NeuronUnit.
+func (l *NeuralLayer) syncValues() {
+
+	for i := range l.NeuronUnits {
+		l.NeuronUnits[i].Net, l.NeuronUnits[i].Value = l.z[i], l.a[i]
+	}
+
+}
+
+// syncDeltas copies deltas of last pattern to Delta of each NeuronUnit.
+func (l *NeuralLayer) syncDeltas() {
+
+	for i := range l.NeuronUnits {
+		l.NeuronUnits[i].Delta = l.d[i]
+	}
+
+}
@@ -4,6 +4,8 @@ package neural
 import (
 
 	// sys import
+	"context"
+	"fmt"
 	"math/rand"
 	"os"
 
@@ -29,9 +31,13 @@ type NeuronUnit struct {
 	Bias float64
 	// Lrate represents learning rate of neuron
 	Lrate float64
+	// Optimizer represents algorithm used by TrainNeuron to update weights (nil is plain SGD)
+	Optimizer Optimizer
 
 	// Value represents desired value when loading input into network in Multi NeuralLayer Perceptron
 	Value float64
+	// Net represents weighted input (pre-activation) of unit in Multi NeuralLayer Perceptron
+	Net float64
 	// Delta represents delta error for unit
 	Delta float64
 
@@ -47,21 +53,24 @@ func init() {
 }
 
 // RandomNeuronInit initialize neuron weight, bias and learning rate using NormFloat64 random value.
-func RandomNeuronInit(neuron *NeuronUnit, dim int) {
+// [rng:rand.Rand] is the source of random values (nil uses a generator seeded with current time)
+func RandomNeuronInit(neuron *NeuronUnit, dim int, rng *rand.Rand) {
+
+	rng = mu.Rand(rng)
 
 	neuron.Weights = make([]float64, dim)
 
 	// init random weights
 	for index, _ := range neuron.Weights {
 		// init random threshold weight
-		neuron.Weights[index] = rand.NormFloat64() * SCALING_FACTOR
+		neuron.Weights[index] = rng.NormFloat64() * SCALING_FACTOR
 	}
 
 	// init random bias and lrate
-	neuron.Bias  = rand.NormFloat64() * SCALING_FACTOR
-	neuron.Lrate = rand.NormFloat64() * SCALING_FACTOR
-	neuron.Value = rand.NormFloat64() * SCALING_FACTOR
-	neuron.Delta = rand.NormFloat64() * SCALING_FACTOR
+	neuron.Bias  = rng.NormFloat64() * SCALING_FACTOR
+	neuron.Lrate = rng.NormFloat64() * SCALING_FACTOR
+	neuron.Value = rng.NormFloat64() * SCALING_FACTOR
+	neuron.Delta = rng.NormFloat64() * SCALING_FACTOR
 
 	log.WithFields(log.Fields{
 		"level":   "debug",
@@ -73,20 +82,56 @@ func RandomNeuronInit(neuron *NeuronUnit, dim int) {
 
 }
 
-// UpdateWeights performs update in neuron weights with respect to passed pattern.
+// Clone returns an independent copy of the neuron, with the same weights, learning rate and optimizer state.
+// Optimizers that are not built-in are copied by their Clone() Optimizer method, if any, otherwise shared.
+func (neuron *NeuronUnit) Clone() *NeuronUnit {
+
+	return &NeuronUnit{
+		Weights:   append([]float64(nil), neuron.Weights...),
+		Bias:      neuron.Bias,
+		Lrate:     neuron.Lrate,
+		Optimizer: cloneOptimizer(neuron.Optimizer),
+	}
+
+}
+
+// UpdateWeights performs update in neuron weights with respect to passed pattern, using optimizer of neuron.
 // It returns error of prediction before and after updating weights.
 func UpdateWeights(neuron *NeuronUnit, pattern *Pattern) (float64, float64) {
+	return updateWeights(neuron, pattern, neuron.Lrate)
+}
+
+// updateWeights performs update in neuron weights with respect to passed pattern, using learning rate lr.
+// It returns error of prediction before and after updating weights.
+func updateWeights(neuron *NeuronUnit, pattern *Pattern, lr float64) (float64, float64) {
 
 	// compute prediction value and error for pattern given neuron BEFORE update (actual state)
 	var predictedValue, prevError, postError float64 = Predict(neuron, pattern), 0.0, 0.0
 	prevError = pattern.SingleExpectation - predictedValue
 
 	// performs weights update for neuron
-	neuron.Bias = neuron.Bias + neuron.Lrate*prevError
+	if neuron.Optimizer == nil {
+
+		neuron.Bias = neuron.Bias + lr*prevError
+		for index, _ := range neuron.Weights {
+			neuron.Weights[index] = neuron.Weights[index] + lr*prevError*pattern.Features[index]
+		}
+
+	} else {
+
+		// derivative of the perceptron criterion is the opposite of error (times feature)
+		grads := make([]float64, len(neuron.Weights))
+		for index, _ := range neuron.Weights {
+			grads[index] = -prevError * pattern.Features[index]
+		}
+		bias := []float64{neuron.Bias}
+
+		// weights are group 0 and bias group 1
+		neuron.Optimizer.Step()
+		neuron.Optimizer.Update(0, neuron.Weights, grads, lr)
+		neuron.Optimizer.Update(1, bias, []float64{-prevError}, lr)
+		neuron.Bias = bias[0]
 
-	// performs weights update for neuron
-	for index, _ := range neuron.Weights {
-		neuron.Weights[index] = neuron.Weights[index] + neuron.Lrate*prevError*pattern.Features[index]
 	}
 
 	// compute prediction value and error for pattern given neuron AFTER update (actual state)
@@ -106,49 +151,208 @@ func UpdateWeights(neuron *NeuronUnit, pattern *Pattern) (float64, float64) {
 
 }
 
-// TrainNeuron trains a passed neuron with patterns passed, for specified number of epoch.
+// TrainNeuron trains a passed neuron with patterns passed, for at most specified number of epoch.
 // If init is 0, leaves weights unchanged before training.
-// If init is 1, reset weights and bias of neuron before training.
-func TrainNeuron(neuron *NeuronUnit, patterns []Pattern, epochs int, init int) {
+// If init is 1, reset weights and bias of neuron (and state of its optimizer) before training.
+// Learning rate changes, training stops early and callbacks are called as specified by optional training settings.
+// It returns the history of training, with mean squared error of predictions as loss.
+func TrainNeuron(neuron *NeuronUnit, patterns []Pattern, epochs int, init int, opts ...TrainOptions) History {
+
+	h, err := TrainNeuronContext(context.Background(), neuron, patterns, epochs, init, opts...)
+	logTrainError("TrainNeuron", err)
+	return h
+
+}
+
+// TrainNeuronContext is like TrainNeuron but stops cleanly after a pattern when ctx is done:
+// in that case neuron keeps the weights reached and an InterruptedError is returned with the history so far.
+// It returns an error if there are no patterns.
+func TrainNeuronContext(ctx context.Context, neuron *NeuronUnit, patterns []Pattern, epochs int, init int, opts ...TrainOptions) (History, error) {
+
+	if len(patterns) == 0 {
+		return History{}, fmt.Errorf("neural: cannot train TrainNeuron on no patterns")
+	}
+
+	// patterns are shown in order, one at a time
+	opt := trainOptions(opts)
+	train, validation := opt.trainingSets(patterns)
+	return trainNeuron(ctx, neuron, &sliceReader{train: train}, validation, len(patterns[0].Features), epochs, init, opt)
+
+}
+
+// TrainNeuronSource is like TrainNeuron, reading training patterns from src in each epoch instead of keeping them
+// in memory: only patterns given as TrainOptions.Validation are used for validation.
+func TrainNeuronSource(neuron *NeuronUnit, src PatternSource, epochs int, init int, opts ...TrainOptions) History {
+
+	h, err := TrainNeuronSourceContext(context.Background(), neuron, src, epochs, init, opts...)
+	logTrainError("TrainNeuron", err)
+	return h
+
+}
+
+// TrainNeuronSourceContext is like TrainNeuronSource but stops cleanly after a pattern when ctx is done,
+// as TrainNeuronContext. It also returns the error of src, if reading it fails.
+func TrainNeuronSourceContext(ctx context.Context, neuron *NeuronUnit, src PatternSource, epochs int, init int, opts ...TrainOptions) (History, error) {
+
+	// number of features, from the first pattern
+	dim := len(neuron.Weights)
+	if init == 1 {
+		if err := src.Reset(); err != nil {
+			return History{}, err
+		}
+		p, ok := src.Next()
+		if !ok {
+			if err := src.Err(); err != nil {
+				return History{}, err
+			}
+			return History{}, fmt.Errorf("neural: cannot train TrainNeuron on an empty source")
+		}
+		dim = len(p.Features)
+	}
+
+	opt := trainOptions(opts)
+	return trainNeuron(ctx, neuron, &sourceReader{src: src, size: 1}, opt.Validation, dim, epochs, init, opt)
+
+}
+
+// trainNeuron trains neuron on patterns of dim features read from data in each epoch, as TrainNeuronContext.
+func trainNeuron(ctx context.Context, neuron *NeuronUnit, data epochReader, validation []Pattern, dim int, epochs int, init int, opt TrainOptions) (h History, err error) {
+
+	m := monitor{es: opt.EarlyStopping}
+	cs := opt.callbacks(LogCallback{})
+	s := &TrainState{Method: "TrainNeuron", Epochs: epochs, History: &h, Neuron: neuron, Patterns: data.patterns(), src: opt.random(), data: data}
+	var best *NeuronUnit
+	s.snapshot = func(c *Checkpoint) {
+		c.neuron = neuron.snapshot()
+		if best != nil {
+			c.bestNeuron = best.snapshot()
+		}
+	}
 
 	// init weights if specified
 	if init == 1 {
-		neuron.Weights = make([]float64, len(patterns[0].Features))
+		neuron.Weights = make([]float64, dim)
 		neuron.Bias = 0.0
+		if neuron.Optimizer != nil {
+			if err = neuron.Optimizer.SetState(OptimizerState{Name: neuron.Optimizer.Name()}); err != nil {
+				return h, fmt.Errorf("neural: cannot reset optimizer of TrainNeuron: %v", err)
+			}
+		}
 	}
 
-	// init counter
-	var epoch int = 0
+	// continue from checkpoint
+	start := 0
+	if c := opt.Resume; c != nil {
+		if c.neuron == nil {
+			return h, fmt.Errorf("neural: cannot resume TrainNeuron from a checkpoint of %s", c.Method)
+		}
+		if err = neuron.fromSnapshot(c.neuron); err != nil {
+			return
+		}
+		if c.bestNeuron != nil {
+			best = &NeuronUnit{}
+			if err = best.fromSnapshot(c.bestNeuron); err != nil {
+				return
+			}
+		}
+		if start, err = c.resume(s, &m, opt.Schedule); err != nil {
+			return
+		}
+	}
 
-	// accumulator errors prev and post weights updates
-	var squaredPrevError, squaredPostError float64 = 0.0, 0.0
+	cs.trainBegin(s)
 
 	// in each epoch
-	for epoch < epochs {
+	for epoch := start; epoch < epochs && !s.Stop; epoch++ {
+
+		s.Epoch = epoch
+		stats := EpochStats{Epoch: epoch}
+		seen := 0
+		cs.epochBegin(s)
+
+		// update weight using each pattern in training set, with scheduled learning rate
+		if err = data.begin(nil); err != nil {
+			cs.trainEnd(s)
+			return
+		}
+		for b := 0; ; b++ {
+			var batch []*Pattern
+			if batch, err = data.next(); err != nil {
+				cs.trainEnd(s)
+				return
+			}
+			if len(batch) == 0 {
+				break
+			}
 
-		// update weight using each pattern in training set
-		for _, pattern := range patterns {
-			prevError, postError := UpdateWeights(neuron, &pattern)
 			// NOTE: in each step, use weights already updated by previous
-			squaredPrevError = squaredPrevError + (prevError * prevError)
-			squaredPostError = squaredPostError + (postError * postError)
+			prevError, _ := updateWeights(neuron, batch[0], scheduledRate(opt.Schedule, neuron.Lrate, epoch, s.Step))
+			stats.TrainLoss += prevError * prevError
+			if prevError == 0.0 {
+				stats.TrainAccuracy++
+			}
+			seen++
+
+			// each pattern is a batch
+			s.Batch, s.BatchLoss = b, prevError * prevError
+			s.Step++
+			cs.batchEnd(s)
+			if s.Stop {
+				break
+			}
+
+			// stop cleanly if context is done
+			if err = interrupted(ctx, s); err != nil {
+				cs.trainEnd(s)
+				return
+			}
 		}
 
-		log.WithFields(log.Fields{
-			"level":            "debug",
-			"place":            "error evolution in epoch",
-			"method":           "TrainNeuron",
-			"msg":              "epoch and squared errors reached before and after updating weights",
-			"epochReached":     epoch + 1,
-			"squaredErrorPrev": squaredPrevError,
-			"squaredErrorPost": squaredPostError,
-		}).Debug()
-
-		// increment epoch counter
-		epoch++
+		stats.TrainLoss /= float64(seen)
+		stats.TrainAccuracy /= float64(seen)
+		stats.LearningRate = scheduledRate(opt.Schedule, neuron.Lrate, epoch, s.Step)
+
+		// evaluate on validation set
+		if len(validation) > 0 {
+			stats.Validated = true
+			for _, pattern := range validation {
+				e := pattern.SingleExpectation - Predict(neuron, &pattern)
+				stats.ValidationLoss += e * e
+				if e == 0.0 {
+					stats.ValidationAccuracy++
+				}
+			}
+			stats.ValidationLoss /= float64(len(validation))
+			stats.ValidationAccuracy /= float64(len(validation))
+		}
+
+		h.Epochs = append(h.Epochs, stats)
+		observeMetric(opt.Schedule, stats.monitored())
+
+		// check early stopping
+		improved, stop := m.observe(&h, epoch, stats.monitored())
+		if improved && opt.EarlyStopping != nil && opt.EarlyStopping.RestoreBest {
+			best = &NeuronUnit{Weights: append([]float64(nil), neuron.Weights...), Bias: neuron.Bias}
+		}
+		if stop {
+			h.Stopped, s.Stop = true, true
+		}
+
+		s.Stats = stats
+		cs.epochEnd(s)
 
 	}
 
+	// restore weights of best epoch
+	if best != nil && h.BestEpoch != len(h.Epochs)-1 {
+		copy(neuron.Weights, best.Weights)
+		neuron.Bias = best.Bias
+	}
+
+	cs.trainEnd(s)
+
+	return
+
 }
 
 // Predict performs a neuron prediction to passed pattern.
@@ -162,7 +366,8 @@ func Predict(neuron *NeuronUnit, pattern *Pattern) float64 {
 
 }
 
-// Accuracy calculate percentage of equal values between two float64 based slices.
+// Accuracy calculate percentage of equal values between two float64 based slices: values are compared as classes,
+// up to rounding errors (see util.SameClass). See package metrics for other measures of classifiers.
 // It returns int number and a float64 percentage value of corrected values.
 func Accuracy(actual []float64, predicted []float64) (int, float64) {
 
@@ -183,7 +388,7 @@ func Accuracy(actual []float64, predicted []float64) (int, float64) {
 	var correct int = 0
 
 	for index, value := range actual {
-		if value == predicted[index] {
+		if mu.SameClass(value, predicted[index]) {
 			correct++
 		}
 	}
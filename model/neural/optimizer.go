@@ -0,0 +1,329 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"fmt"
+	"math"
+
+)
+
+// Optimizer represents an algorithm to update parameters given the derivative of the loss.
+// Optimizers keep a state for each parameter, so an instance must not be shared between models.
+type Optimizer interface {
+
+	// Name returns the name used to serialize the optimizer
+	Name() string
+	// Step advances the time step of the optimizer: it is called once before each update of all parameters
+	Step()
+	// Update updates params in place, given derivative of the loss grads and learning rate lr.
+	// [id:int] identifies the group of parameters, to keep their state between updates: even ids are weights,
+	// odd ids are biases
+	Update(id int, params []float64, grads []float64, lr float64)
+	// State returns a copy of the state of the optimizer
+	State() OptimizerState
+	// SetState restores a state returned by State
+	SetState(s OptimizerState) error
+
+}
+
+// OptimizerState represents the persistent state of an Optimizer.
+type OptimizerState struct {
+
+	// Name represents name of optimizer
+	Name string `json:"name"`
+	// Hyper represents hyper parameters of optimizer
+	Hyper map[string]float64 `json:"hyper,omitempty"`
+	// Steps represents number of time steps done
+	Steps int `json:"steps"`
+	// Slots represents per parameter state (e.g. velocity, moments) of each group of parameters
+	Slots map[int][][]float64 `json:"slots,omitempty"`
+
+}
+
+const (
+
+	// optimizerEpsilon avoids division by zero in adaptive optimizers
+	optimizerEpsilon = 1e-8
+
+)
+
+// #######################################################################################
+
+// SGD returns a stochastic gradient descent optimizer with momentum (0 for plain SGD).
+func SGD(momentum float64) Optimizer {
+	return &sgd{optimizerSlots: newOptimizerSlots(), momentum: momentum}
+}
+
+// Nesterov returns a stochastic gradient descent optimizer with Nesterov momentum.
+func Nesterov(momentum float64) Optimizer {
+	return &sgd{optimizerSlots: newOptimizerSlots(), momentum: momentum, nesterov: true}
+}
+
+// AdaGrad returns an optimizer with per parameter learning rate scaled by the sum of past squared gradients.
+func AdaGrad() Optimizer {
+	return &adaGrad{optimizerSlots: newOptimizerSlots()}
+}
+
+// RMSProp returns an optimizer with per parameter learning rate scaled by a moving average of squared gradients.
+// [rho:float64] is the decay of the moving average (usually 0.9)
+func RMSProp(rho float64) Optimizer {
+	return &rmsProp{optimizerSlots: newOptimizerSlots(), rho: rho}
+}
+
+// Adam returns an adaptive moment estimation optimizer.
+// [beta1:float64], [beta2:float64] are the decays of the first and second moments (usually 0.9 and 0.999)
+func Adam(beta1 float64, beta2 float64) Optimizer {
+	return &adam{optimizerSlots: newOptimizerSlots(), beta1: beta1, beta2: beta2}
+}
+
+// AdamW returns an Adam optimizer with decoupled weight decay.
+// [decay:float64] is the weight decay, applied to weights proportionally to learning rate (biases are not decayed)
+func AdamW(beta1 float64, beta2 float64, decay float64) Optimizer {
+	return &adam{optimizerSlots: newOptimizerSlots(), beta1: beta1, beta2: beta2, decay: decay}
+}
+
+// OptimizerFromState creates a built-in optimizer restoring a state returned by its State method.
+func OptimizerFromState(s OptimizerState) (Optimizer, error) {
+
+	var o Optimizer
+	h := s.Hyper
+	switch s.Name {
+	case "sgd":
+		o = SGD(h["momentum"])
+	case "nesterov":
+		o = Nesterov(h["momentum"])
+	case "adagrad":
+		o = AdaGrad()
+	case "rmsprop":
+		o = RMSProp(h["rho"])
+	case "adam":
+		o = Adam(h["beta1"], h["beta2"])
+	case "adamw":
+		o = AdamW(h["beta1"], h["beta2"], h["decay"])
+	default:
+		return nil, fmt.Errorf("neural: unknown optimizer %q", s.Name)
+	}
+
+	return o, o.SetState(s)
+
+}
+
+// cloneOptimizer returns an independent copy of o with the same state, nil if o is nil.
+// Optimizers that are not built-in are copied with their Clone() Optimizer method, if any, otherwise o is
+// shared with the copy, so training one updates the state of both.
+func cloneOptimizer(o Optimizer) Optimizer {
+
+	if o == nil {
+		return nil
+	}
+	if c, ok := o.(interface{ Clone() Optimizer }); ok {
+		return c.Clone()
+	}
+
+	c, err := OptimizerFromState(o.State())
+	if err != nil {
+		return o
+	}
+	return c
+
+}
+
+// optimizerSlots keeps time step and per parameter state of built-in optimizers.
+type optimizerSlots struct {
+	steps int
+	slots map[int][][]float64
+}
+
+func newOptimizerSlots() optimizerSlots {
+	return optimizerSlots{slots: map[int][][]float64{}}
+}
+
+func (o *optimizerSlots) Step() { o.steps++ }
+
+// get returns k slots of n values for group id, allocating them if needed.
+func (o *optimizerSlots) get(id int, n int, k int) [][]float64 {
+
+	s, ok := o.slots[id]
+	if !ok || len(s) != k || len(s[0]) != n {
+		s = make([][]float64, k)
+		for i := range s {
+			s[i] = make([]float64, n)
+		}
+		o.slots[id] = s
+	}
+	return s
+
+}
+
+// state returns a deep copy of slots as OptimizerState.
+func (o *optimizerSlots) state(name string, hyper map[string]float64) OptimizerState {
+
+	s := OptimizerState{Name: name, Hyper: hyper, Steps: o.steps, Slots: make(map[int][][]float64, len(o.slots))}
+	for id, vs := range o.slots {
+		s.Slots[id] = make([][]float64, len(vs))
+		for i, v := range vs {
+			s.Slots[id][i] = append([]float64(nil), v...)
+		}
+	}
+	return s
+
+}
+
+// setState restores a deep copy of slots from OptimizerState.
+func (o *optimizerSlots) setState(name string, s OptimizerState) error {
+
+	if s.Name != name {
+		return fmt.Errorf("neural: cannot restore %q state into %q optimizer", s.Name, name)
+	}
+	o.steps = s.Steps
+	o.slots = make(map[int][][]float64, len(s.Slots))
+	for id, vs := range s.Slots {
+		o.slots[id] = make([][]float64, len(vs))
+		for i, v := range vs {
+			o.slots[id][i] = append([]float64(nil), v...)
+		}
+	}
+	return nil
+
+}
+
+// different type of optimizer
+
+type sgd struct {
+	optimizerSlots
+	momentum float64
+	nesterov bool
+}
+
+func (o *sgd) Name() string {
+
+	if o.nesterov {
+		return "nesterov"
+	}
+	return "sgd"
+
+}
+
+func (o *sgd) Update(id int, params []float64, grads []float64, lr float64) {
+
+	// plain stochastic gradient descent, no state
+	if o.momentum == 0 && !o.nesterov {
+		for i := range params {
+			params[i] -= lr * grads[i]
+		}
+		return
+	}
+
+	v := o.get(id, len(params), 1)[0]
+	for i := range params {
+		pv := v[i]
+		v[i] = o.momentum*v[i] - lr*grads[i]
+		if o.nesterov {
+			// look ahead along the velocity
+			params[i] += -o.momentum*pv + (1+o.momentum)*v[i]
+		} else {
+			params[i] += v[i]
+		}
+	}
+
+}
+
+func (o *sgd) State() OptimizerState {
+	return o.state(o.Name(), map[string]float64{"momentum": o.momentum})
+}
+
+func (o *sgd) SetState(s OptimizerState) error { return o.setState(o.Name(), s) }
+
+type adaGrad struct {
+	optimizerSlots
+}
+
+func (o *adaGrad) Name() string { return "adagrad" }
+
+func (o *adaGrad) Update(id int, params []float64, grads []float64, lr float64) {
+
+	a := o.get(id, len(params), 1)[0]
+	for i := range params {
+		a[i] += grads[i] * grads[i]
+		params[i] -= lr * grads[i] / (math.Sqrt(a[i]) + optimizerEpsilon)
+	}
+
+}
+
+func (o *adaGrad) State() OptimizerState { return o.state(o.Name(), nil) }
+
+func (o *adaGrad) SetState(s OptimizerState) error { return o.setState(o.Name(), s) }
+
+type rmsProp struct {
+	optimizerSlots
+	rho float64
+}
+
+func (o *rmsProp) Name() string { return "rmsprop" }
+
+func (o *rmsProp) Update(id int, params []float64, grads []float64, lr float64) {
+
+	a := o.get(id, len(params), 1)[0]
+	for i := range params {
+		a[i] = o.rho*a[i] + (1-o.rho)*grads[i]*grads[i]
+		params[i] -= lr * grads[i] / (math.Sqrt(a[i]) + optimizerEpsilon)
+	}
+
+}
+
+func (o *rmsProp) State() OptimizerState {
+	return o.state(o.Name(), map[string]float64{"rho": o.rho})
+}
+
+func (o *rmsProp) SetState(s OptimizerState) error { return o.setState(o.Name(), s) }
+
+type adam struct {
+	optimizerSlots
+	beta1 float64
+	beta2 float64
+	decay float64
+}
+
+func (o *adam) Name() string {
+
+	if o.decay != 0 {
+		return "adamw"
+	}
+	return "adam"
+
+}
+
+func (o *adam) Update(id int, params []float64, grads []float64, lr float64) {
+
+	s := o.get(id, len(params), 2)
+	m, v := s[0], s[1]
+
+	// bias corrections, time step starts from 1
+	t := float64(o.steps)
+	if t < 1 {
+		t = 1
+	}
+	c1, c2 := 1-math.Pow(o.beta1, t), 1-math.Pow(o.beta2, t)
+
+	// decoupled weight decay, of weights only
+	decay := o.decay
+	if id%2 == 1 {
+		decay = 0
+	}
+
+	for i := range params {
+		m[i] = o.beta1*m[i] + (1-o.beta1)*grads[i]
+		v[i] = o.beta2*v[i] + (1-o.beta2)*grads[i]*grads[i]
+		params[i] -= lr * decay * params[i]
+		params[i] -= lr * (m[i] / c1) / (math.Sqrt(v[i]/c2) + optimizerEpsilon)
+	}
+
+}
+
+func (o *adam) State() OptimizerState {
+	return o.state(o.Name(), map[string]float64{"beta1": o.beta1, "beta2": o.beta2, "decay": o.decay})
+}
+
+func (o *adam) SetState(s OptimizerState) error { return o.setState(o.Name(), s) }
@@ -0,0 +1,148 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+)
+
+// optimizerTolerance represents the largest difference between updates and the ones computed by hand,
+// which ignore optimizerEpsilon
+const optimizerTolerance = 1e-6
+
+// signOnly represents an optimizer that is not built-in and has no Clone method
+type signOnly struct{}
+
+// #######################################################################################
+
+func (signOnly) Name() string { return "sign_only" }
+
+func (signOnly) Step() {}
+
+func (signOnly) Update(id int, params []float64, grads []float64, lr float64) {
+	for i := range params {
+		params[i] -= lr * math.Copysign(1, grads[i])
+	}
+}
+
+func (signOnly) State() OptimizerState { return OptimizerState{Name: "sign_only"} }
+
+func (signOnly) SetState(s OptimizerState) error { return nil }
+
+// TestOptimizerSteps checks the parameters updated by each built-in optimizer against values computed by hand,
+// after each of two steps with the same derivatives.
+func TestOptimizerSteps(t *testing.T) {
+
+	grads, lr := []float64{0.5, -1}, 0.1
+	cases := []struct {
+		name string
+		o    Optimizer
+		id   int
+		want [][]float64
+	}{
+		{"sgd", SGD(0), 0, [][]float64{{0.95, -1.9}, {0.9, -1.8}}},
+		// v = 0.9 v - lr g
+		{"momentum", SGD(0.9), 0, [][]float64{{0.95, -1.9}, {0.855, -1.71}}},
+		// p += -0.9 v' + 1.9 v, where v' is the previous velocity
+		{"nesterov", Nesterov(0.9), 0, [][]float64{{0.905, -1.81}, {0.7695, -1.539}}},
+		// a = sum of g^2, p -= lr g / sqrt(a)
+		{"adagrad", AdaGrad(), 0, [][]float64{{0.9, -1.9}, {0.9 - 0.1/math.Sqrt(2), -1.9 + 0.1/math.Sqrt(2)}}},
+		// a = 0.9 a + 0.1 g^2, p -= lr g / sqrt(a)
+		{"rmsprop", RMSProp(0.9), 0, [][]float64{{1 - 0.1/math.Sqrt(0.1), -2 + 0.1/math.Sqrt(0.1)},
+			{1 - 0.1/math.Sqrt(0.1) - 0.05/math.Sqrt(0.0475), -2 + 0.1/math.Sqrt(0.1) + 0.1/math.Sqrt(0.19)}}},
+		// with constant derivatives, bias corrected moments are g and g^2: p -= lr sign(g)
+		{"adam", Adam(0.9, 0.999), 0, [][]float64{{0.9, -1.9}, {0.8, -1.8}}},
+		// weights decay by lr * decay before the step of Adam
+		{"adamw weights", AdamW(0.9, 0.999, 0.01), 0, [][]float64{{0.899, -1.898}, {0.899*0.999 - 0.1, -1.898*0.999 + 0.1}}},
+		// biases do not decay
+		{"adamw biases", AdamW(0.9, 0.999, 0.01), 1, [][]float64{{0.9, -1.9}, {0.8, -1.8}}},
+	}
+
+	for _, c := range cases {
+
+		params := []float64{1, -2}
+		for s, want := range c.want {
+			c.o.Step()
+			c.o.Update(c.id, params, grads, lr)
+			for i := range params {
+				if math.Abs(params[i]-want[i]) > optimizerTolerance {
+					t.Errorf("%s: parameter %d after step %d is %v, want %v", c.name, i, s+1, params[i], want[i])
+				}
+			}
+		}
+
+	}
+
+}
+
+// TestOptimizerState checks that an optimizer restored from its state updates parameters as the original one,
+// and that the state is a copy.
+func TestOptimizerState(t *testing.T) {
+
+	for _, o := range []Optimizer{SGD(0.9), Nesterov(0.8), AdaGrad(), RMSProp(0.9), Adam(0.9, 0.999), AdamW(0.9, 0.99, 0.1)} {
+
+		// a few steps on two groups of parameters
+		params := [][]float64{{1, -2, 0.5}, {0.3}}
+		for s := 0; s < 3; s++ {
+			o.Step()
+			o.Update(0, params[0], []float64{0.1 * float64(s), -1, 2}, 0.01)
+			o.Update(1, params[1], []float64{-0.5}, 0.01)
+		}
+
+		st := o.State()
+		restored, err := OptimizerFromState(st)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(restored.State(), st) {
+			t.Errorf("%s: state restored %+v, saved %+v", o.Name(), restored.State(), st)
+		}
+		if c := cloneOptimizer(o); c == o || !reflect.DeepEqual(c.State(), st) {
+			t.Errorf("%s: clone is not an independent copy with the same state", o.Name())
+		}
+
+		// both take the same next step, and the state saved does not change
+		saved := fmt.Sprint(st)
+		a, b := append([]float64(nil), params[0]...), append([]float64(nil), params[0]...)
+		o.Step()
+		restored.Step()
+		o.Update(0, a, []float64{0.7, 0.2, -0.4}, 0.01)
+		restored.Update(0, b, []float64{0.7, 0.2, -0.4}, 0.01)
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("%s: restored optimizer updates parameters to %v, original one to %v", o.Name(), b, a)
+		}
+		if fmt.Sprint(st) != saved {
+			t.Errorf("%s: state saved changes with the optimizer", o.Name())
+		}
+
+	}
+
+	// a state can not be restored in another optimizer
+	if err := SGD(0).SetState(AdaGrad().State()); err == nil {
+		t.Error("state of adagrad restored into sgd")
+	}
+
+	if _, err := OptimizerFromState(OptimizerState{Name: "sign_only"}); err == nil {
+		t.Error("unknown optimizer restored")
+	}
+
+}
+
+// TestCloneOptimizerShared checks that an optimizer that is not built-in and has no Clone method is shared.
+func TestCloneOptimizerShared(t *testing.T) {
+
+	if c := cloneOptimizer(signOnly{}); c != (signOnly{}) {
+		t.Errorf("clone of optimizer without Clone method is %v", c)
+	}
+
+	neuron := NeuronUnit{Weights: []float64{1, 2}, Optimizer: signOnly{}}
+	if c := neuron.Clone(); c.Optimizer != neuron.Optimizer {
+		t.Errorf("clone of neuron has optimizer %v", c.Optimizer)
+	}
+
+}
@@ -0,0 +1,112 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"sync"
+
+)
+
+// dataParallel computes derivatives of a batch sharding it across replicas of a network, one for each worker.
+// Replicas share weights of the network and have their own buffers, so they run at the same time.
+type dataParallel struct {
+	replicas []*MultiLayerNetwork
+	losses   []float64
+	y        []float64
+}
+
+// #######################################################################################
+
+// newDataParallel prepares workers replicas of mlp.
+func newDataParallel(mlp *MultiLayerNetwork, workers int) *dataParallel {
+
+	dp := &dataParallel{}
+	for w := 0; w < workers; w++ {
+		dp.replicas = append(dp.replicas, mlp.replica())
+	}
+	return dp
+
+}
+
+// replica returns a network with the layers of mlp and its own buffers, without weights: see share.
+func (mlp *MultiLayerNetwork) replica() *MultiLayerNetwork {
+
+	r := &MultiLayerNetwork{L_rate: mlp.L_rate, Loss: mlp.Loss, NeuralLayers: make([]NeuralLayer, len(mlp.NeuralLayers))}
+	for k, l := range mlp.NeuralLayers {
+		r.NeuralLayers[k] = NeuralLayer{NeuronUnits: make([]NeuronUnit, l.Length), Length: l.Length, T_func: l.T_func}
+	}
+	return r
+
+}
+
+// share makes replica r use current weights and biases of mlp, already packed: weights are shared, not copied.
+func (r *MultiLayerNetwork) share(mlp *MultiLayerNetwork) {
+
+	for k := range mlp.NeuralLayers {
+		r.NeuralLayers[k].W = mlp.NeuralLayers[k].W
+		copy(r.NeuralLayers[k].NeuronUnits, mlp.NeuralLayers[k].NeuronUnits)
+	}
+
+}
+
+// computeBatchGradients is like ComputeBatchGradients, splitting the batch in contiguous shards, one for each replica,
+// that propagate them at the same time. Then each worker accumulates derivatives of a part of the neurons of each
+// layer over the whole batch, in order of pattern: results are the same as the ones of ComputeBatchGradients,
+// bit for bit, whatever the number of workers.
+func (dp *dataParallel) computeBatchGradients(mlp *MultiLayerNetwork, s []*Pattern, o [][]float64, g *Gradients) []float64 {
+
+	n, w := len(s), len(dp.replicas)
+	if w > n {
+		w = n
+	}
+	mlp.pack()
+	if len(dp.losses) != n {
+		dp.losses = make([]float64, n)
+	}
+
+	// each worker propagates its shard on its replica
+	var wg sync.WaitGroup
+	for r := 0; r < w; r++ {
+		lo, hi := r*n/w, (r+1)*n/w
+		dp.replicas[r].share(mlp)
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			propagateBatch(dp.replicas[r], s[lo:hi], o[lo:hi], dp.losses[lo:hi])
+		}(r)
+	}
+	wg.Wait()
+
+	// each worker accumulates derivatives of its neurons of each layer, from all shards in order
+	nets := dp.replicas[:w]
+	for r := 0; r < w; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			for k := 1; k < len(mlp.NeuralLayers); k++ {
+				m := mlp.NeuralLayers[k].Length
+				if lo, hi := r*m/w, (r+1)*m/w; lo < hi {
+					addBatchGradients(g, k, lo, hi, nets)
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	// losses and outputs in order
+	for _, l := range dp.losses {
+		g.Loss += l
+	}
+	g.Count += n
+	m := mlp.NeuralLayers[len(mlp.NeuralLayers)-1].Length
+	if len(dp.y) != n*m {
+		dp.y = make([]float64, n*m)
+	}
+	for r := 0; r < w; r++ {
+		copy(dp.y[r*n/w*m:], nets[r].NeuralLayers[len(mlp.NeuralLayers)-1].ab)
+	}
+
+	return dp.y
+
+}
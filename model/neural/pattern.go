@@ -3,10 +3,11 @@ package neural
 
 import (
 	// sys import
-	"encoding/csv"
-	"io"
+	"bytes"
 	"io/ioutil"
+	"math/rand"
 	"os"
+	"strconv"
 	"strings"
 
 	// third part import
@@ -23,7 +24,8 @@ func init() {
 	log.SetLevel(log.InfoLevel)
 }
 
-// Pattern struct represents one pattern with dimensions and desired value
+// Pattern struct represents one pattern with dimensions and desired value.
+// A Dataset keeps patterns together with names and types of their features and the encoding of classes.
 type Pattern struct {
 
 	// Features that describe the pattern
@@ -39,7 +41,8 @@ type Pattern struct {
 
 // #######################################################################################
 
-// LoadPatternsFromCSVFile load a CSV dataset into an array of Pattern.
+// LoadPatternsFromCSVFile load a CSV dataset into an array of Pattern, with the class name in the last column.
+// Use LoadDatasetFromCSVFile to load files with header, other delimiters or target column.
 func LoadPatternsFromCSVFile(filePath string) ([]Pattern, error, []string) {
 
 	// init patterns
@@ -60,58 +63,18 @@ func LoadPatternsFromCSVFile(filePath string) ([]Pattern, error, []string) {
 		return patterns, error, nil
 	}
 
-	// create pointer to read file
-	pointer := csv.NewReader(strings.NewReader(string(fileContent)))
-
-	var lineCounter int = 0
-	// for each record in file
-	for {
-
-		// read line, check error
-		line, error := pointer.Read()
-
+	// missing or unparsable values are read as NaN, keeping columns in place
+	patterns, _, error = readCSVPatterns(bytes.NewReader(fileContent), CSVOptions{})
+	if error != nil {
 		log.WithFields(log.Fields{
-			"level":  "debug",
-			"place":  "patterns",
-			"method": "LoadPatternsFromCSVFile",
-			"line":   line,
-		}).Debug()
-
-		// if end of file reached, exit loop
-		if error == io.EOF {
-			log.WithFields(log.Fields{
-				"level":    "info",
-				"place":    "patterns",
-				"method":   "LoadPatternsFromCSVFile",
-				"readData": len(patterns),
-				"msg":      "File reading completed.",
-			}).Info("File reading completed.")
-			break
-		}
-
-		// if another error encountered, exit program
-		if error != nil {
-			log.WithFields(log.Fields{
-				"level":       "error",
-				"place":       "patterns",
-				"method":      "LoadPatternsFromCSVFile",
-				"msg":         "parsing file in specific line number",
-				"lineCounter": lineCounter,
-				"error":       error,
-			}).Error("Failed to parse line.")
-			return patterns, error, nil
-		}
-
-		// line values cast to float64
-		var floatingValues []float64 = mu.StringToFloat(line, 1, -1.0)
-
-		// add casted pattern to training set
-		patterns = append(
-			patterns,
-			Pattern{Features: floatingValues, SingleRawExpectation: line[len(line)-1]})
-
-		lineCounter = lineCounter + 1
-
+			"level":    "error",
+			"place":    "patterns",
+			"method":   "LoadPatternsFromCSVFile",
+			"msg":      "parsing file in specific path",
+			"filePath": filePath,
+			"error":    error,
+		}).Error("Failed to parse file.")
+		return patterns, error, nil
 	}
 
 	// cast expected values to float64 numeric values
@@ -166,8 +129,42 @@ func RawExpectedConversion(patterns []Pattern) []string {
 
 }
 
-// CreateRandomPatternArray load a CSV dataset into an array of Pattern.
-func CreateRandomPatternArray(d int, k int) ([]Pattern) {
+// ClassGroups returns indexes of patterns grouped by class, classes in order of appearance and indexes in order.
+// The class of a pattern is its SingleExpectation, or the combination of its MultipleExpectation for multi-label
+// and sequence patterns.
+func ClassGroups(patterns []Pattern) [][]int {
+
+	var groups [][]int
+	index := map[string]int{}
+	for i := range patterns {
+
+		key := strconv.FormatFloat(patterns[i].SingleExpectation, 'g', -1, 64)
+		if m := patterns[i].MultipleExpectation; len(m) > 0 {
+			key = ""
+			for _, v := range m {
+				key += strconv.FormatFloat(v, 'g', -1, 64) + ","
+			}
+		}
+
+		g, ok := index[key]
+		if !ok {
+			g = len(groups)
+			index[key] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+
+	}
+	return groups
+
+}
+
+// CreateRandomPatternArray creates k patterns of the "learn to sum" task: features are the binary digits
+// of two random numbers of d bits, drawn from rng (nil uses a generator seeded with current time),
+// expected outputs the d+1 binary digits of their sum (and SingleExpectation the sum itself).
+func CreateRandomPatternArray(d int, k int, rng *rand.Rand) ([]Pattern) {
+
+	rng = mu.Rand(rng)
 
 	// init patterns
 	var patterns []Pattern;
@@ -176,8 +173,8 @@ func CreateRandomPatternArray(d int, k int) ([]Pattern) {
 	var i = 0
 	for i < k {
 
-		a := mu.GenerateRandomIntWithBinaryDim(d)
-		b := mu.GenerateRandomIntWithBinaryDim(d)
+		a := mu.GenerateRandomIntWithBinaryDim(d, rng)
+		b := mu.GenerateRandomIntWithBinaryDim(d, rng)
 		c := a+b
 
 		log.WithFields(log.Fields{
@@ -199,6 +196,7 @@ func CreateRandomPatternArray(d int, k int) ([]Pattern) {
 		patterns = append(
 			patterns,
 			Pattern{Features: ab,
+				SingleExpectation:	float64(c),
 				MultipleExpectation: 	mu.ConvertIntToBinary(c, d+1)})
 
 		i = i + 1
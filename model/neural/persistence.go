@@ -0,0 +1,348 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+
+	// FormatVersion is the version of the serialization format written by Save and SaveBinary.
+	// Models written with another version are rejected.
+	FormatVersion = 1
+
+	// formatName identifies JSON encoded models
+	formatName = "go-perceptron-go"
+	// formatMagic identifies binary encoded models
+	formatMagic = "GPGO"
+
+	// kinds of model that can be serialized
+	kindMLP        = "mlp"
+	kindNeuron     = "neuron"
+	kindCheckpoint = "checkpoint"
+
+)
+
+// envelope is the JSON header that wraps every serialized model.
+type envelope struct {
+	Format  string          `json:"format"`
+	Version int             `json:"version"`
+	Kind    string          `json:"kind"`
+	Model   json.RawMessage `json:"model"`
+}
+
+// layerSnapshot represents the persistent state of a NeuralLayer.
+type layerSnapshot struct {
+	Neurons    int         `json:"neurons"`
+	Activation string      `json:"activation,omitempty"`
+	Weights    [][]float64 `json:"weights"`
+	Biases     []float64   `json:"biases"`
+}
+
+// mlpSnapshot represents the persistent state of a MultiLayerNetwork.
+type mlpSnapshot struct {
+	LearningRate float64            `json:"learning_rate"`
+	Loss         string             `json:"loss,omitempty"`
+	Optimizer    *OptimizerState    `json:"optimizer,omitempty"`
+	Layers       []layerSnapshot    `json:"layers"`
+	Pipeline     []TransformerState `json:"pipeline,omitempty"`
+}
+
+// neuronSnapshot represents the persistent state of a NeuronUnit.
+type neuronSnapshot struct {
+	Weights      []float64       `json:"weights"`
+	Bias         float64         `json:"bias"`
+	LearningRate float64         `json:"learning_rate"`
+	Optimizer    *OptimizerState `json:"optimizer,omitempty"`
+}
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+// Save writes the network to w using the versioned JSON format.
+func (mlp *MultiLayerNetwork) Save(w io.Writer) error {
+	return mlp.save(w, false)
+}
+
+// SaveBinary writes the network to w using the versioned compact binary format.
+func (mlp *MultiLayerNetwork) SaveBinary(w io.Writer) error {
+	return mlp.save(w, true)
+}
+
+// save takes a snapshot of the network and writes it in the requested format.
+func (mlp *MultiLayerNetwork) save(w io.Writer, bin bool) error {
+	return writeModel(w, kindMLP, bin, mlp.snapshot())
+}
+
+// snapshot takes a copy of the network.
+func (mlp *MultiLayerNetwork) snapshot() *mlpSnapshot {
+
+	s := &mlpSnapshot{LearningRate: mlp.L_rate, Layers: make([]layerSnapshot, len(mlp.NeuralLayers))}
+	if mlp.Loss != nil {
+		s.Loss = mlp.Loss.Name()
+	}
+	s.Optimizer = optimizerSnapshot(mlp.Optimizer)
+	s.Pipeline = mlp.Pipeline.snapshot()
+
+	// for each layer take a copy of transfer function, weights and biases
+	for k, l := range mlp.NeuralLayers {
+		s.Layers[k] = layerSnapshot{Neurons: l.Length, Weights: make([][]float64, l.Length), Biases: make([]float64, l.Length)}
+		if l.T_func != nil {
+			s.Layers[k].Activation = l.T_func.Name()
+		}
+		for i, n := range l.NeuronUnits {
+			s.Layers[k].Weights[i] = append([]float64(nil), n.Weights...)
+			s.Layers[k].Biases[i] = n.Bias
+		}
+	}
+
+	return s
+
+}
+
+// Load replaces the network with the one read from r.
+// Both JSON and binary formats are accepted.
+func (mlp *MultiLayerNetwork) Load(r io.Reader) error {
+
+	var s mlpSnapshot
+	if err := readModel(r, kindMLP, &s); err != nil {
+		return err
+	}
+
+	if err := mlp.fromSnapshot(&s); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"level":  "info",
+		"place":  "persistence",
+		"method": "Load",
+		"layers": len(mlp.NeuralLayers),
+	}).Info("Multilayer Perceptron loaded.")
+
+	return nil
+
+}
+
+// fromSnapshot replaces the network with a copy of snapshot s.
+func (mlp *MultiLayerNetwork) fromSnapshot(s *mlpSnapshot) (err error) {
+
+	layers := make([]NeuralLayer, len(s.Layers))
+	for k, ls := range s.Layers {
+		if len(ls.Weights) != ls.Neurons || len(ls.Biases) != ls.Neurons {
+			return fmt.Errorf("neural: layer %d is inconsistent with its %d neurons", k, ls.Neurons)
+		}
+		// a weight for each unit of the previous layer, none in the input layer
+		width := 0
+		if k > 0 {
+			width = s.Layers[k-1].Neurons
+		}
+		for i, w := range ls.Weights {
+			if len(w) != width {
+				return fmt.Errorf("neural: neuron %d of layer %d has %d weights, previous layer %d neurons", i, k, len(w), width)
+			}
+		}
+		layers[k] = NeuralLayer{NeuronUnits: make([]NeuronUnit, ls.Neurons), Length: ls.Neurons}
+		if ls.Activation != "" {
+			if layers[k].T_func, err = ActivationByName(ls.Activation); err != nil {
+				return err
+			}
+		}
+		for i := range layers[k].NeuronUnits {
+			layers[k].NeuronUnits[i] = NeuronUnit{Weights: append([]float64(nil), ls.Weights[i]...), Bias: ls.Biases[i]}
+		}
+	}
+
+	// loss function, optimizer with its state and preprocessing of features, if any
+	var loss Loss
+	if s.Loss != "" {
+		if loss, err = LossByName(s.Loss); err != nil {
+			return err
+		}
+	}
+
+	opt, err := optimizerFromSnapshot(s.Optimizer)
+	if err != nil {
+		return err
+	}
+
+	pipeline, err := pipelineFromSnapshot(s.Pipeline)
+	if err != nil {
+		return err
+	}
+
+	mlp.L_rate = s.LearningRate
+	mlp.NeuralLayers = layers
+	mlp.Loss = loss
+	mlp.Optimizer = opt
+	mlp.Pipeline = pipeline
+
+	return nil
+
+}
+
+// optimizerSnapshot returns the state of optimizer o, nil if o is nil.
+func optimizerSnapshot(o Optimizer) *OptimizerState {
+
+	if o == nil {
+		return nil
+	}
+	st := o.State()
+	return &st
+
+}
+
+// optimizerFromSnapshot restores an optimizer from its state, nil if s is nil.
+func optimizerFromSnapshot(s *OptimizerState) (Optimizer, error) {
+
+	if s == nil {
+		return nil, nil
+	}
+	return OptimizerFromState(*s)
+
+}
+
+// Save writes the neuron to w using the versioned JSON format.
+func (neuron *NeuronUnit) Save(w io.Writer) error {
+	return writeModel(w, kindNeuron, false, neuron.snapshot())
+}
+
+// SaveBinary writes the neuron to w using the versioned compact binary format.
+func (neuron *NeuronUnit) SaveBinary(w io.Writer) error {
+	return writeModel(w, kindNeuron, true, neuron.snapshot())
+}
+
+// snapshot takes a copy of the neuron.
+func (neuron *NeuronUnit) snapshot() *neuronSnapshot {
+	return &neuronSnapshot{Weights: append([]float64(nil), neuron.Weights...), Bias: neuron.Bias, LearningRate: neuron.Lrate, Optimizer: optimizerSnapshot(neuron.Optimizer)}
+}
+
+// Load replaces the neuron with the one read from r.
+// Both JSON and binary formats are accepted.
+func (neuron *NeuronUnit) Load(r io.Reader) error {
+
+	var s neuronSnapshot
+	if err := readModel(r, kindNeuron, &s); err != nil {
+		return err
+	}
+	return neuron.fromSnapshot(&s)
+
+}
+
+// fromSnapshot replaces the neuron with a copy of snapshot s.
+func (neuron *NeuronUnit) fromSnapshot(s *neuronSnapshot) error {
+
+	opt, err := optimizerFromSnapshot(s.Optimizer)
+	if err != nil {
+		return err
+	}
+
+	*neuron = NeuronUnit{Weights: append([]float64(nil), s.Weights...), Bias: s.Bias, Lrate: s.LearningRate, Optimizer: opt}
+
+	return nil
+
+}
+
+// writeModel writes the header and the snapshot v of the given kind.
+// If bin is true the compact binary format is used, otherwise JSON.
+func writeModel(w io.Writer, kind string, bin bool, v interface{}) error {
+
+	if !bin {
+		m, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(envelope{Format: formatName, Version: FormatVersion, Kind: kind, Model: m})
+	}
+
+	// binary header: magic, version, kind length and kind
+	var h bytes.Buffer
+	h.WriteString(formatMagic)
+	binary.Write(&h, binary.LittleEndian, uint16(FormatVersion))
+	h.WriteByte(byte(len(kind)))
+	h.WriteString(kind)
+	if _, err := w.Write(h.Bytes()); err != nil {
+		return err
+	}
+
+	return gob.NewEncoder(w).Encode(v)
+
+}
+
+// readModel reads the header, detecting the format, and decodes the snapshot of the given kind into v.
+func readModel(r io.Reader, kind string, v interface{}) error {
+
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(len(formatMagic))
+	if err != nil {
+		return fmt.Errorf("neural: reading model header: %v", err)
+	}
+
+	// JSON format
+	if string(magic) != formatMagic {
+		var e envelope
+		if err := json.NewDecoder(br).Decode(&e); err != nil {
+			return fmt.Errorf("neural: decoding model: %v", err)
+		}
+		if e.Format != formatName {
+			return fmt.Errorf("neural: unknown model format %q", e.Format)
+		}
+		if err := checkHeader(e.Version, e.Kind, kind); err != nil {
+			return err
+		}
+		return json.Unmarshal(e.Model, v)
+	}
+
+	// binary format
+	br.Discard(len(formatMagic))
+	var version uint16
+	if err := binary.Read(br, binary.LittleEndian, &version); err != nil {
+		return fmt.Errorf("neural: reading model version: %v", err)
+	}
+	kl, err := br.ReadByte()
+	if err != nil {
+		return fmt.Errorf("neural: reading model kind: %v", err)
+	}
+	k := make([]byte, kl)
+	if _, err := io.ReadFull(br, k); err != nil {
+		return fmt.Errorf("neural: reading model kind: %v", err)
+	}
+	if err := checkHeader(int(version), string(k), kind); err != nil {
+		return err
+	}
+
+	return gob.NewDecoder(br).Decode(v)
+
+}
+
+// checkHeader verifies that version and kind read from a stream can be decoded as expected kind.
+func checkHeader(version int, kind string, expected string) error {
+
+	if version != FormatVersion {
+		return fmt.Errorf("neural: unsupported model format version %d (want %d)", version, FormatVersion)
+	}
+	if kind != expected {
+		return fmt.Errorf("neural: stream contains a %q model, expected %q", kind, expected)
+	}
+	return nil
+
+}
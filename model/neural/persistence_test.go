@@ -0,0 +1,141 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"bytes"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+)
+
+// versionOnePayload represents a network saved with version 1 of the JSON format: 2 inputs, a sigmoid output.
+const versionOnePayload = `{"format":"go-perceptron-go","version":1,"kind":"mlp","model":{"learning_rate":0.1,` +
+	`"layers":[{"neurons":2,"weights":[null,null],"biases":[0,0]},` +
+	`{"neurons":1,"activation":"sigmoid","weights":[[0.5,-0.25]],"biases":[0.1]}]}}`
+
+// #######################################################################################
+
+// trainedNetwork returns a network with an activation per layer, a loss, an optimizer with state and a pipeline.
+func trainedNetwork() *MultiLayerNetwork {
+
+	rng := rand.New(rand.NewSource(4))
+	mlp := PrepareMLPNetFromSpec([]LayerSpec{{Neurons: 2}, {Neurons: 5, Activation: Tanh}, {Neurons: 2, Activation: Softmax}}, 0.05, rng)
+	mlp.Loss = CrossEntropy
+	mlp.Optimizer = Adam(0.9, 0.999)
+	mlp.Pipeline = NewPipeline(StandardScaler())
+	MLPTrain(&mlp, sourcePatterns(), []string{"0", "1"}, 3, TrainOptions{Seed: 1, Callbacks: []Callback{}})
+	return &mlp
+
+}
+
+// TestSaveLoadNetwork checks that a network loaded back, from JSON and binary, is the one saved.
+func TestSaveLoadNetwork(t *testing.T) {
+
+	mlp := trainedNetwork()
+	for _, bin := range []bool{false, true} {
+
+		var b bytes.Buffer
+		if err := mlp.save(&b, bin); err != nil {
+			t.Fatal(err)
+		}
+		var loaded MultiLayerNetwork
+		if err := loaded.Load(&b); err != nil {
+			t.Fatalf("binary %v: %v", bin, err)
+		}
+
+		if !reflect.DeepEqual(mlp.snapshot(), loaded.snapshot()) {
+			t.Errorf("binary %v: network loaded differs from the one saved", bin)
+		}
+		for _, p := range sourcePatterns() {
+			if want, got := mlp.Predict(p.Features), loaded.Predict(p.Features); !reflect.DeepEqual(want, got) {
+				t.Fatalf("binary %v: prediction of %v is %v, %v before saving", bin, p.Features, got, want)
+			}
+		}
+
+	}
+
+}
+
+// TestSaveLoadNeuron checks that a neuron loaded back, from JSON and binary, is the one saved.
+func TestSaveLoadNeuron(t *testing.T) {
+
+	neuron := NeuronUnit{Lrate: 0.1, Optimizer: SGD(0.9)}
+	RandomNeuronInit(&neuron, 2, rand.New(rand.NewSource(2)))
+	TrainNeuron(&neuron, sourcePatterns(), 3, 0, TrainOptions{Seed: 1, Callbacks: []Callback{}})
+
+	for _, bin := range []bool{false, true} {
+
+		var b bytes.Buffer
+		save := neuron.Save
+		if bin {
+			save = neuron.SaveBinary
+		}
+		if err := save(&b); err != nil {
+			t.Fatal(err)
+		}
+		var loaded NeuronUnit
+		if err := loaded.Load(&b); err != nil {
+			t.Fatalf("binary %v: %v", bin, err)
+		}
+		if !reflect.DeepEqual(neuron.snapshot(), loaded.snapshot()) {
+			t.Errorf("binary %v: neuron loaded %+v differs from the one saved %+v", bin, loaded.snapshot(), neuron.snapshot())
+		}
+
+	}
+
+}
+
+// TestLoadFormatVersions checks that files of version 1 keep loading, and files of other versions are rejected.
+func TestLoadFormatVersions(t *testing.T) {
+
+	var mlp MultiLayerNetwork
+	if err := mlp.Load(strings.NewReader(versionOnePayload)); err != nil {
+		t.Fatal(err)
+	}
+	want := 1 / (1 + math.Exp(-(0.5*1-0.25*2+0.1)))
+	if y := mlp.Predict([]float64{1, 2}); math.Abs(y[0]-want) > 1e-15 {
+		t.Errorf("network of version 1 predicts %v, want %v", y[0], want)
+	}
+
+	for _, v := range []string{`"version":0`, `"version":2`} {
+		payload := strings.Replace(versionOnePayload, `"version":1`, v, 1)
+		if err := mlp.Load(strings.NewReader(payload)); err == nil || !strings.Contains(err.Error(), "version") {
+			t.Errorf("payload with %s loads with error %v, want unsupported version", v, err)
+		}
+	}
+
+	// the binary header carries the version after the magic
+	var b bytes.Buffer
+	if err := mlp.SaveBinary(&b); err != nil {
+		t.Fatal(err)
+	}
+	raw := b.Bytes()
+	raw[len(formatMagic)] = 2
+	if err := mlp.Load(bytes.NewReader(raw)); err == nil {
+		t.Error("binary payload of version 2 loads without error")
+	}
+
+}
+
+// TestLoadInconsistentWeights checks that files whose rows of weights do not match the previous layer are rejected.
+func TestLoadInconsistentWeights(t *testing.T) {
+
+	payloads := map[string]string{
+		"short row":        strings.Replace(versionOnePayload, `[[0.5,-0.25]]`, `[[0.5]]`, 1),
+		"long row":         strings.Replace(versionOnePayload, `[[0.5,-0.25]]`, `[[0.5,-0.25,1]]`, 1),
+		"weights of input": strings.Replace(versionOnePayload, `[null,null]`, `[[1],null]`, 1),
+		"missing bias":     strings.Replace(versionOnePayload, `"biases":[0.1]`, `"biases":[]`, 1),
+	}
+	for name, payload := range payloads {
+		var mlp MultiLayerNetwork
+		if err := mlp.Load(strings.NewReader(payload)); err == nil {
+			t.Errorf("%s: network loads without error", name)
+		}
+	}
+
+}
@@ -0,0 +1,735 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+)
+
+// Transformer represents a preprocessing step of features, with parameters learnt from training features.
+// Transformers keep the parameters learnt, so an instance must not be shared between models.
+type Transformer interface {
+
+	// Name returns the name used to serialize the transformer
+	Name() string
+	// Fit learns parameters of the transformer from features of training patterns
+	Fit(features [][]float64) error
+	// Transform returns transformed features, leaving features unchanged
+	Transform(features []float64) []float64
+	// State returns a copy of the state of the transformer
+	State() TransformerState
+	// SetState restores a state returned by State
+	SetState(s TransformerState) error
+
+}
+
+// TransformerState represents the persistent state of a Transformer.
+type TransformerState struct {
+
+	// Name represents name of transformer
+	Name string `json:"name"`
+	// Columns represents features transformed (empty is all)
+	Columns []int `json:"columns,omitempty"`
+	// Params represents parameters learnt, by name, one per feature transformed
+	Params map[string][]float64 `json:"params,omitempty"`
+	// Categories represents categories of each feature transformed, for encoders
+	Categories [][]float64 `json:"categories,omitempty"`
+
+}
+
+// ImputeStrategy represents the value used by an Imputer to replace missing values.
+type ImputeStrategy int
+
+// Pipeline represents preprocessing of features: a chain of transformers, each one fitted on the output
+// of the previous one. Set as MultiLayerNetwork.Pipeline, it is fitted on training patterns by MLPTrain
+// and ElmanTrain, applied by Predict and saved with the network.
+type Pipeline struct {
+
+	// Steps represents transformers, in order of application
+	Steps []Transformer
+
+}
+
+// strategies of imputation
+const (
+
+	// ImputeMean replaces missing values with the mean of the feature
+	ImputeMean ImputeStrategy = iota
+	// ImputeMedian replaces missing values with the median of the feature
+	ImputeMedian
+	// ImputeMode replaces missing values with the most frequent value of the feature
+	ImputeMode
+
+)
+
+// kindPipeline is the kind of a serialized Pipeline
+const kindPipeline = "pipeline"
+
+// columnScaler scales each feature as (x - offset) / scale, the kind of scaling depending on name.
+type columnScaler struct {
+	name    string
+	columns []int
+	offset  []float64
+	scale   []float64
+}
+
+// l2Normalizer scales features of each pattern to unit euclidean norm.
+type l2Normalizer struct{}
+
+// oneHot replaces categorical features with one indicator feature per category.
+type oneHot struct {
+	columns    []int
+	fixed      bool
+	categories [][]float64
+}
+
+// imputer replaces missing (NaN) values of features.
+type imputer struct {
+	strategy ImputeStrategy
+	columns  []int
+	values   []float64
+}
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+func (s ImputeStrategy) String() string {
+
+	switch s {
+	case ImputeMean:
+		return "mean"
+	case ImputeMedian:
+		return "median"
+	case ImputeMode:
+		return "mode"
+	}
+	return fmt.Sprintf("ImputeStrategy(%d)", int(s))
+
+}
+
+// MinMaxScaler returns a transformer that scales features between 0 and 1, by their minimum and maximum.
+// [columns:...int] are the features scaled (none is all); missing values are left as they are
+func MinMaxScaler(columns ...int) Transformer {
+	return &columnScaler{name: "minmax", columns: columns}
+}
+
+// StandardScaler returns a transformer that scales features to z-scores, by their mean and standard deviation.
+// [columns:...int] are the features scaled (none is all); missing values are left as they are
+func StandardScaler(columns ...int) Transformer {
+	return &columnScaler{name: "zscore", columns: columns}
+}
+
+// RobustScaler returns a transformer that scales features by their median and interquartile range,
+// so that outliers have little effect on the scaling.
+// [columns:...int] are the features scaled (none is all); missing values are left as they are
+func RobustScaler(columns ...int) Transformer {
+	return &columnScaler{name: "robust", columns: columns}
+}
+
+// L2Normalizer returns a transformer that scales the features of each pattern to unit euclidean norm.
+// It learns nothing: patterns with all features 0 are left as they are, missing values are ignored.
+func L2Normalizer() Transformer {
+	return l2Normalizer{}
+}
+
+// OneHotEncoder returns a transformer that replaces each categorical feature with one feature per category,
+// 1 for the category of the pattern and 0 for the others. Categories are the values seen in training, in
+// numeric order: unknown and missing values have all features 0. Use Dataset.OneHotEncoder to encode
+// all the categories of a dataset, so that the number of features does not depend on training patterns.
+// [columns:...int] are the categorical features (none is all)
+func OneHotEncoder(columns ...int) Transformer {
+	return &oneHot{columns: columns}
+}
+
+// Imputer returns a transformer that replaces missing (NaN) values with a statistic of the feature
+// in training patterns (see ImputeStrategy), 0 if all its values are missing.
+// [columns:...int] are the features imputed (none is all)
+func Imputer(strategy ImputeStrategy, columns ...int) Transformer {
+	return &imputer{strategy: strategy, columns: columns}
+}
+
+// TransformerFromState creates a built-in transformer restoring a state returned by its State method.
+func TransformerFromState(s TransformerState) (Transformer, error) {
+
+	var t Transformer
+	switch s.Name {
+	case "minmax":
+		t = MinMaxScaler()
+	case "zscore":
+		t = StandardScaler()
+	case "robust":
+		t = RobustScaler()
+	case "l2":
+		t = L2Normalizer()
+	case "onehot":
+		t = OneHotEncoder()
+	case "impute-mean":
+		t = Imputer(ImputeMean)
+	case "impute-median":
+		t = Imputer(ImputeMedian)
+	case "impute-mode":
+		t = Imputer(ImputeMode)
+	default:
+		return nil, fmt.Errorf("neural: unknown transformer %q", s.Name)
+	}
+
+	return t, t.SetState(s)
+
+}
+
+// cloneTransformer returns an independent copy of t with the same state.
+// Transformers that are not built-in are copied with their Clone() Transformer method, if any.
+func cloneTransformer(t Transformer) Transformer {
+
+	if c, ok := t.(interface{ Clone() Transformer }); ok {
+		return c.Clone()
+	}
+
+	c, err := TransformerFromState(t.State())
+	if err != nil {
+		panic(fmt.Sprintf("neural: cannot clone transformer %q, it needs a Clone() Transformer method", t.Name()))
+	}
+	return c
+
+}
+
+// selectColumns returns columns, or all the n features if columns is empty.
+// It returns an error if a column is not one of the n features.
+func selectColumns(columns []int, n int) ([]int, error) {
+
+	if len(columns) == 0 {
+		return identityColumns(n), nil
+	}
+	for _, j := range columns {
+		if j < 0 || j >= n {
+			return nil, fmt.Errorf("neural: column %d out of %d features", j, n)
+		}
+	}
+	return columns, nil
+
+}
+
+// identityColumns returns indexes from 0 to n-1.
+func identityColumns(n int) []int {
+
+	c := make([]int, n)
+	for j := range c {
+		c[j] = j
+	}
+	return c
+
+}
+
+// observed returns values of column j of features, without missing ones, in increasing order.
+func observed(features [][]float64, j int) []float64 {
+
+	var v []float64
+	for _, x := range features {
+		if j < len(x) && !math.IsNaN(x[j]) {
+			v = append(v, x[j])
+		}
+	}
+	sort.Float64s(v)
+	return v
+
+}
+
+// quantile returns the q-quantile of sorted values, interpolating linearly between them.
+func quantile(sorted []float64, q float64) float64 {
+
+	if len(sorted) == 0 {
+		return 0
+	}
+	h := q * float64(len(sorted)-1)
+	i := int(h)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (h-float64(i))*(sorted[i+1]-sorted[i])
+
+}
+
+// width returns the number of features of the first pattern, 0 without patterns.
+func width(features [][]float64) int {
+
+	if len(features) == 0 {
+		return 0
+	}
+	return len(features[0])
+
+}
+
+func (c *columnScaler) Name() string { return c.name }
+
+// Fit learns offset and scale of each column; constant or missing columns get scale 1.
+func (c *columnScaler) Fit(features [][]float64) error {
+
+	columns, err := selectColumns(c.columns, width(features))
+	if err != nil {
+		return err
+	}
+
+	c.offset, c.scale = make([]float64, len(columns)), make([]float64, len(columns))
+	for k, j := range columns {
+
+		v := observed(features, j)
+		c.scale[k] = 1
+		if len(v) == 0 {
+			continue
+		}
+
+		switch c.name {
+		case "minmax":
+			c.offset[k], c.scale[k] = v[0], v[len(v)-1]-v[0]
+		case "zscore":
+			mean, sd := 0.0, 0.0
+			for _, x := range v {
+				mean += x
+			}
+			mean /= float64(len(v))
+			for _, x := range v {
+				sd += (x - mean) * (x - mean)
+			}
+			c.offset[k], c.scale[k] = mean, math.Sqrt(sd/float64(len(v)))
+		case "robust":
+			c.offset[k], c.scale[k] = quantile(v, .5), quantile(v, .75)-quantile(v, .25)
+		}
+		if c.scale[k] == 0 {
+			c.scale[k] = 1
+		}
+
+	}
+	return nil
+
+}
+
+func (c *columnScaler) Transform(features []float64) []float64 {
+
+	y := append([]float64(nil), features...)
+	columns := c.columns
+	if len(columns) == 0 {
+		columns = identityColumns(len(c.offset))
+	}
+	for k, j := range columns {
+		if k < len(c.offset) && j < len(y) {
+			y[j] = (y[j] - c.offset[k]) / c.scale[k]
+		}
+	}
+	return y
+
+}
+
+func (c *columnScaler) State() TransformerState {
+
+	return TransformerState{
+		Name:    c.name,
+		Columns: append([]int(nil), c.columns...),
+		Params:  map[string][]float64{"offset": append([]float64(nil), c.offset...), "scale": append([]float64(nil), c.scale...)},
+	}
+
+}
+
+func (c *columnScaler) SetState(s TransformerState) error {
+
+	if s.Name != c.name {
+		return fmt.Errorf("neural: cannot restore state of %q into %q", s.Name, c.name)
+	}
+	offset, scale := s.Params["offset"], s.Params["scale"]
+	if len(offset) != len(scale) || (len(s.Columns) > 0 && len(offset) > 0 && len(offset) != len(s.Columns)) {
+		return fmt.Errorf("neural: %s state is inconsistent with its columns", c.name)
+	}
+	c.columns = append([]int(nil), s.Columns...)
+	c.offset, c.scale = append([]float64(nil), offset...), append([]float64(nil), scale...)
+	return nil
+
+}
+
+func (l2Normalizer) Name() string { return "l2" }
+
+func (l2Normalizer) Fit(features [][]float64) error { return nil }
+
+func (l2Normalizer) Transform(features []float64) []float64 {
+
+	norm := 0.0
+	for _, x := range features {
+		if !math.IsNaN(x) {
+			norm += x * x
+		}
+	}
+	y := append([]float64(nil), features...)
+	if norm == 0 {
+		return y
+	}
+	norm = math.Sqrt(norm)
+	for j := range y {
+		y[j] /= norm
+	}
+	return y
+
+}
+
+func (n l2Normalizer) State() TransformerState { return TransformerState{Name: n.Name()} }
+
+func (n l2Normalizer) SetState(s TransformerState) error {
+
+	if s.Name != n.Name() {
+		return fmt.Errorf("neural: cannot restore state of %q into %q", s.Name, n.Name())
+	}
+	return nil
+
+}
+
+func (o *oneHot) Name() string { return "onehot" }
+
+// Fit learns categories of each column, unless they were given.
+func (o *oneHot) Fit(features [][]float64) error {
+
+	columns, err := selectColumns(o.columns, width(features))
+	if err != nil {
+		return err
+	}
+	if o.fixed {
+		return nil
+	}
+
+	o.categories = make([][]float64, len(columns))
+	for k, j := range columns {
+		v := observed(features, j)
+		for i, x := range v {
+			if i == 0 || x != v[i-1] {
+				o.categories[k] = append(o.categories[k], x)
+			}
+		}
+	}
+	return nil
+
+}
+
+// Transform replaces encoded columns with their indicators, in place: other features keep their order.
+func (o *oneHot) Transform(features []float64) []float64 {
+
+	columns := o.columns
+	if len(columns) == 0 {
+		columns = identityColumns(len(o.categories))
+	}
+	encoded := make(map[int][]float64, len(columns))
+	for k, j := range columns {
+		if k < len(o.categories) {
+			encoded[j] = o.categories[k]
+		}
+	}
+
+	y := make([]float64, 0, len(features))
+	for j, x := range features {
+		categories, ok := encoded[j]
+		if !ok {
+			y = append(y, x)
+			continue
+		}
+		for _, c := range categories {
+			if x == c {
+				y = append(y, 1)
+			} else {
+				y = append(y, 0)
+			}
+		}
+	}
+	return y
+
+}
+
+func (o *oneHot) State() TransformerState {
+
+	s := TransformerState{Name: o.Name(), Columns: append([]int(nil), o.columns...), Categories: make([][]float64, len(o.categories))}
+	for k, c := range o.categories {
+		s.Categories[k] = append([]float64{}, c...)
+	}
+	if o.fixed {
+		s.Params = map[string][]float64{"fixed": {1}}
+	}
+	return s
+
+}
+
+func (o *oneHot) SetState(s TransformerState) error {
+
+	if s.Name != o.Name() {
+		return fmt.Errorf("neural: cannot restore state of %q into %q", s.Name, o.Name())
+	}
+	if len(s.Columns) > 0 && len(s.Categories) > 0 && len(s.Categories) != len(s.Columns) {
+		return fmt.Errorf("neural: %s state is inconsistent with its columns", o.Name())
+	}
+	o.columns = append([]int(nil), s.Columns...)
+	o.fixed = len(s.Params["fixed"]) > 0
+	o.categories = make([][]float64, len(s.Categories))
+	for k, c := range s.Categories {
+		o.categories[k] = append([]float64{}, c...)
+	}
+	return nil
+
+}
+
+func (m *imputer) Name() string { return "impute-" + m.strategy.String() }
+
+func (m *imputer) Fit(features [][]float64) error {
+
+	columns, err := selectColumns(m.columns, width(features))
+	if err != nil {
+		return err
+	}
+
+	m.values = make([]float64, len(columns))
+	for k, j := range columns {
+
+		v := observed(features, j)
+		if len(v) == 0 {
+			continue
+		}
+
+		switch m.strategy {
+		case ImputeMean:
+			for _, x := range v {
+				m.values[k] += x
+			}
+			m.values[k] /= float64(len(v))
+		case ImputeMedian:
+			m.values[k] = quantile(v, .5)
+		case ImputeMode:
+			// values are sorted: the longest run is the mode, the smallest one in case of ties
+			best, run := 0, 0
+			for i := range v {
+				if i > 0 && v[i] == v[i-1] {
+					run++
+				} else {
+					run = 1
+				}
+				if run > best {
+					best, m.values[k] = run, v[i]
+				}
+			}
+		}
+
+	}
+	return nil
+
+}
+
+func (m *imputer) Transform(features []float64) []float64 {
+
+	y := append([]float64(nil), features...)
+	columns := m.columns
+	if len(columns) == 0 {
+		columns = identityColumns(len(m.values))
+	}
+	for k, j := range columns {
+		if k < len(m.values) && j < len(y) && math.IsNaN(y[j]) {
+			y[j] = m.values[k]
+		}
+	}
+	return y
+
+}
+
+func (m *imputer) State() TransformerState {
+
+	return TransformerState{
+		Name:    m.Name(),
+		Columns: append([]int(nil), m.columns...),
+		Params:  map[string][]float64{"values": append([]float64(nil), m.values...)},
+	}
+
+}
+
+func (m *imputer) SetState(s TransformerState) error {
+
+	if s.Name != m.Name() {
+		return fmt.Errorf("neural: cannot restore state of %q into %q", s.Name, m.Name())
+	}
+	values := s.Params["values"]
+	if len(s.Columns) > 0 && len(values) > 0 && len(values) != len(s.Columns) {
+		return fmt.Errorf("neural: %s state is inconsistent with its columns", m.Name())
+	}
+	m.columns = append([]int(nil), s.Columns...)
+	m.values = append([]float64(nil), values...)
+	return nil
+
+}
+
+// NewPipeline returns a pipeline of the given transformers, applied in order.
+func NewPipeline(steps ...Transformer) *Pipeline {
+	return &Pipeline{Steps: steps}
+}
+
+// Fit fits each transformer on features of patterns, transformed by the previous ones.
+func (p *Pipeline) Fit(patterns []Pattern) error {
+
+	features := make([][]float64, len(patterns))
+	for i := range patterns {
+		features[i] = patterns[i].Features
+	}
+
+	for k, t := range p.Steps {
+		if err := t.Fit(features); err != nil {
+			return fmt.Errorf("%v, fitting step %d (%s) of pipeline", err, k, t.Name())
+		}
+		if k == len(p.Steps)-1 {
+			break
+		}
+		next := make([][]float64, len(features))
+		for i, x := range features {
+			next[i] = t.Transform(x)
+		}
+		features = next
+	}
+
+	log.WithFields(log.Fields{
+		"level":    "debug",
+		"place":    "pipeline",
+		"method":   "Fit",
+		"steps":    len(p.Steps),
+		"patterns": len(patterns),
+	}).Debug("Pipeline fitted.")
+
+	return nil
+
+}
+
+// Transform returns features transformed by each step of the pipeline, leaving features unchanged.
+func (p *Pipeline) Transform(features []float64) []float64 {
+
+	y := features
+	for _, t := range p.Steps {
+		y = t.Transform(y)
+	}
+	if len(p.Steps) == 0 {
+		y = append([]float64(nil), features...)
+	}
+	return y
+
+}
+
+// TransformPatterns returns copies of patterns with transformed features.
+func (p *Pipeline) TransformPatterns(patterns []Pattern) []Pattern {
+
+	t := make([]Pattern, len(patterns))
+	for i := range patterns {
+		t[i] = patterns[i]
+		t[i].Features = p.Transform(patterns[i].Features)
+	}
+	return t
+
+}
+
+// Clone returns an independent copy of the pipeline with the same parameters, nil if p is nil.
+// Transformers that are not built-in need a Clone() Transformer method.
+func (p *Pipeline) Clone() *Pipeline {
+
+	if p == nil {
+		return nil
+	}
+	c := &Pipeline{Steps: make([]Transformer, len(p.Steps))}
+	for k, t := range p.Steps {
+		c.Steps[k] = cloneTransformer(t)
+	}
+	return c
+
+}
+
+// snapshot returns the states of transformers, nil if p is nil.
+func (p *Pipeline) snapshot() []TransformerState {
+
+	if p == nil {
+		return nil
+	}
+	s := make([]TransformerState, len(p.Steps))
+	for k, t := range p.Steps {
+		s[k] = t.State()
+	}
+	return s
+
+}
+
+// pipelineFromSnapshot creates a pipeline of built-in transformers from their states, nil if s is nil.
+func pipelineFromSnapshot(s []TransformerState) (*Pipeline, error) {
+
+	if s == nil {
+		return nil, nil
+	}
+	p := &Pipeline{Steps: make([]Transformer, len(s))}
+	for k := range s {
+		t, err := TransformerFromState(s[k])
+		if err != nil {
+			return nil, err
+		}
+		p.Steps[k] = t
+	}
+	return p, nil
+
+}
+
+// Save writes the pipeline to w using the versioned JSON format (it is also saved with a network using it).
+func (p *Pipeline) Save(w io.Writer) error {
+	return writeModel(w, kindPipeline, false, p.snapshot())
+}
+
+// ReadPipeline reads a pipeline written by Pipeline.Save from r.
+func ReadPipeline(r io.Reader) (*Pipeline, error) {
+
+	var s []TransformerState
+	if err := readModel(r, kindPipeline, &s); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = []TransformerState{}
+	}
+	return pipelineFromSnapshot(s)
+
+}
+
+// OneHotEncoder returns a transformer that encodes categorical features of the dataset (see OneHotEncoder)
+// with all their categories, so that the number of features is the same whatever the training patterns:
+// the indexes of their names, or the values found in the dataset for features without names of categories.
+func (d *Dataset) OneHotEncoder() Transformer {
+
+	o := &oneHot{fixed: true}
+	for j, t := range d.Types {
+		if t != CategoricalFeature {
+			continue
+		}
+		var categories []float64
+		if j < len(d.Categories) && d.Categories[j] != nil {
+			for c := range d.Categories[j] {
+				categories = append(categories, float64(c))
+			}
+		} else {
+			features := make([][]float64, len(d.Patterns))
+			for i := range d.Patterns {
+				features[i] = d.Patterns[i].Features
+			}
+			v := observed(features, j)
+			for i, x := range v {
+				if i == 0 || x != v[i-1] {
+					categories = append(categories, x)
+				}
+			}
+		}
+		o.columns, o.categories = append(o.columns, j), append(o.categories, categories)
+	}
+	return o
+
+}
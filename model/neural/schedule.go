@@ -0,0 +1,204 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"math"
+
+)
+
+// Schedule represents a policy to change the learning rate during training.
+type Schedule interface {
+
+	// Rate returns the learning rate to use given the base learning rate of the model,
+	// the epoch (from 0) and the step, that is the number of updates already done
+	Rate(base float64, epoch int, step int) float64
+
+}
+
+// MetricSchedule represents a Schedule driven by a monitored metric, where lower is better.
+type MetricSchedule interface {
+
+	Schedule
+
+	// Observe is called by trainers at the end of each epoch with the monitored metric
+	// (validation loss if available, training loss otherwise)
+	Observe(metric float64)
+
+}
+
+// #######################################################################################
+
+// StepDecay returns a schedule that multiplies the learning rate by drop every n epochs.
+func StepDecay(drop float64, n int) Schedule {
+	return stepDecay{drop: drop, every: n}
+}
+
+// ExponentialDecay returns a schedule with learning rate base * e^(-k * epoch).
+func ExponentialDecay(k float64) Schedule {
+	return exponentialDecay{k: k}
+}
+
+// InverseTimeDecay returns a schedule with learning rate base / (1 + k * epoch).
+func InverseTimeDecay(k float64) Schedule {
+	return inverseTimeDecay{k: k}
+}
+
+// CosineAnnealing returns a cosine annealing schedule with warm restarts: learning rate goes from base
+// to min in period epochs, then restarts from base with a period mult times longer.
+func CosineAnnealing(min float64, period int, mult int) Schedule {
+	return cosineAnnealing{min: min, period: period, mult: mult}
+}
+
+// LinearWarmup returns a schedule that increases linearly the learning rate from 0 to base in the first steps,
+// then follows next (constant base learning rate if nil).
+func LinearWarmup(steps int, next Schedule) Schedule {
+	return linearWarmup{steps: steps, next: next}
+}
+
+// ReduceOnPlateau returns a schedule that multiplies the learning rate by factor when the monitored metric
+// does not improve by at least minDelta for patience epochs, never going below min.
+func ReduceOnPlateau(factor float64, patience int, minDelta float64, min float64) MetricSchedule {
+	return &reduceOnPlateau{factor: factor, patience: patience, minDelta: minDelta, min: min, scale: 1.0, best: math.Inf(1)}
+}
+
+// scheduledRate returns the learning rate given by schedule s, or base if s is nil.
+func scheduledRate(s Schedule, base float64, epoch int, step int) float64 {
+
+	if s == nil {
+		return base
+	}
+	return s.Rate(base, epoch, step)
+
+}
+
+// observeMetric passes metric to s if it is driven by a metric.
+func observeMetric(s Schedule, metric float64) {
+
+	if ms, ok := s.(MetricSchedule); ok {
+		ms.Observe(metric)
+	}
+
+}
+
+// cloneSchedule returns a copy of s with its own state, so that it can be used by another training at the same time.
+// Schedules that are not built-in are copied with their Clone() Schedule method, if any, otherwise shared:
+// stateful ones, like those driven by a metric, need the method.
+func cloneSchedule(s Schedule) Schedule {
+
+	if c, ok := s.(interface{ Clone() Schedule }); ok {
+		return c.Clone()
+	}
+
+	switch s := s.(type) {
+	case *reduceOnPlateau:
+		c := *s
+		return &c
+	case linearWarmup:
+		return linearWarmup{steps: s.steps, next: cloneSchedule(s.next)}
+	}
+	return s
+
+}
+
+// different type of schedule
+
+type stepDecay struct {
+	drop  float64
+	every int
+}
+
+func (s stepDecay) Rate(base float64, epoch int, step int) float64 {
+
+	if s.every < 1 {
+		return base
+	}
+	return base * math.Pow(s.drop, float64(epoch/s.every))
+
+}
+
+type exponentialDecay struct{ k float64 }
+
+func (s exponentialDecay) Rate(base float64, epoch int, step int) float64 {
+	return base * math.Exp(-s.k*float64(epoch))
+}
+
+type inverseTimeDecay struct{ k float64 }
+
+func (s inverseTimeDecay) Rate(base float64, epoch int, step int) float64 {
+	return base / (1 + s.k*float64(epoch))
+}
+
+type cosineAnnealing struct {
+	min    float64
+	period int
+	mult   int
+}
+
+func (s cosineAnnealing) Rate(base float64, epoch int, step int) float64 {
+
+	if s.period < 1 {
+		return base
+	}
+
+	// find epoch in current cycle and its length
+	t, p := epoch, s.period
+	for t >= p {
+		t -= p
+		if s.mult > 1 {
+			p *= s.mult
+		}
+	}
+
+	return s.min + (base-s.min)*(1+math.Cos(math.Pi*float64(t)/float64(p)))/2
+
+}
+
+type linearWarmup struct {
+	steps int
+	next  Schedule
+}
+
+func (s linearWarmup) Rate(base float64, epoch int, step int) float64 {
+
+	if step < s.steps {
+		return base * float64(step+1) / float64(s.steps)
+	}
+	return scheduledRate(s.next, base, epoch, step)
+
+}
+
+// Observe passes the monitored metric to the schedule followed after warmup.
+func (s linearWarmup) Observe(metric float64) { observeMetric(s.next, metric) }
+
+type reduceOnPlateau struct {
+	factor   float64
+	patience int
+	minDelta float64
+	min      float64
+
+	// scale represents the reduction reached, best the best metric and wait the epochs without improvement
+	scale float64
+	best  float64
+	wait  int
+}
+
+func (s *reduceOnPlateau) Rate(base float64, epoch int, step int) float64 {
+	return math.Max(base*s.scale, s.min)
+}
+
+func (s *reduceOnPlateau) Observe(metric float64) {
+
+	if metric < s.best-s.minDelta {
+		s.best, s.wait = metric, 0
+		return
+	}
+
+	s.wait++
+	if s.wait >= s.patience {
+		s.scale *= s.factor
+		s.wait = 0
+	}
+
+}
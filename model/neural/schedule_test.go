@@ -0,0 +1,134 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"math"
+	"testing"
+
+)
+
+// scheduleBase represents the base learning rate of schedules tested
+const scheduleBase = 0.1
+
+// countingSchedule represents a schedule that is not built-in, with a state and a Clone method
+type countingSchedule struct{ observed int }
+
+// #######################################################################################
+
+func (s *countingSchedule) Rate(base float64, epoch int, step int) float64 { return base }
+
+func (s *countingSchedule) Observe(metric float64) { s.observed++ }
+
+func (s *countingSchedule) Clone() Schedule {
+	c := *s
+	return &c
+}
+
+// TestScheduleRates checks learning rates of built-in schedules at known epochs and steps.
+func TestScheduleRates(t *testing.T) {
+
+	cases := []struct {
+		name        string
+		s           Schedule
+		epoch, step int
+		want        float64
+	}{
+		{"step decay, first epoch", StepDecay(0.5, 10), 0, 0, 0.1},
+		{"step decay, before drop", StepDecay(0.5, 10), 9, 0, 0.1},
+		{"step decay, first drop", StepDecay(0.5, 10), 10, 0, 0.05},
+		{"step decay, second drop", StepDecay(0.5, 10), 25, 0, 0.025},
+		{"step decay, no period", StepDecay(0.5, 0), 25, 0, 0.1},
+		{"exponential decay, first epoch", ExponentialDecay(0.1), 0, 0, 0.1},
+		{"exponential decay", ExponentialDecay(0.1), 10, 0, 0.1 / math.E},
+		{"inverse time decay, first epoch", InverseTimeDecay(0.5), 0, 0, 0.1},
+		{"inverse time decay", InverseTimeDecay(0.5), 2, 0, 0.05},
+		{"inverse time decay, later", InverseTimeDecay(0.5), 6, 0, 0.025},
+		// cycles of 10, 20, 40 epochs from 0.1 to 0.01
+		{"cosine annealing, start", CosineAnnealing(0.01, 10, 2), 0, 0, 0.1},
+		{"cosine annealing, half period", CosineAnnealing(0.01, 10, 2), 5, 0, 0.055},
+		{"cosine annealing, first restart", CosineAnnealing(0.01, 10, 2), 10, 0, 0.1},
+		{"cosine annealing, half second period", CosineAnnealing(0.01, 10, 2), 20, 0, 0.055},
+		{"cosine annealing, second restart", CosineAnnealing(0.01, 10, 2), 30, 0, 0.1},
+		{"cosine annealing, same period", CosineAnnealing(0.01, 10, 1), 25, 0, 0.055},
+		// 4 steps from 0.025 to 0.1, then step decay
+		{"warmup, first step", LinearWarmup(4, StepDecay(0.5, 1)), 0, 0, 0.025},
+		{"warmup, last step", LinearWarmup(4, StepDecay(0.5, 1)), 1, 3, 0.1},
+		{"warmup, after", LinearWarmup(4, StepDecay(0.5, 1)), 2, 4, 0.025},
+		{"warmup, constant after", LinearWarmup(4, nil), 2, 10, 0.1},
+		{"no schedule", nil, 7, 100, 0.1},
+	}
+
+	for _, c := range cases {
+		if r := scheduledRate(c.s, scheduleBase, c.epoch, c.step); math.Abs(r-c.want) > 1e-15 {
+			t.Errorf("%s: learning rate in epoch %d, step %d is %v, want %v", c.name, c.epoch, c.step, r, c.want)
+		}
+	}
+
+}
+
+// TestReduceOnPlateau checks learning rates of ReduceOnPlateau after each metric observed.
+func TestReduceOnPlateau(t *testing.T) {
+
+	// halved after 2 epochs without improving by 0.01, never below 0.02
+	s := ReduceOnPlateau(0.5, 2, 0.01, 0.02)
+	steps := []struct {
+		metric, want float64
+	}{
+		{1.0, 0.1},
+		{0.995, 0.1},
+		{0.999, 0.05},
+		{0.5, 0.05},
+		{0.6, 0.05},
+		{0.7, 0.025},
+		{0.8, 0.025},
+		{0.9, 0.02},
+	}
+
+	for epoch, st := range steps {
+		observeMetric(s, st.metric)
+		if r := scheduledRate(s, scheduleBase, epoch, 0); math.Abs(r-st.want) > 1e-15 {
+			t.Errorf("epoch %d: learning rate after metric %v is %v, want %v", epoch, st.metric, r, st.want)
+		}
+	}
+
+	// metrics reach the schedule followed after warmup
+	w := LinearWarmup(1, ReduceOnPlateau(0.5, 1, 0, 0))
+	observeMetric(w, 1)
+	observeMetric(w, 2)
+	if r := w.Rate(scheduleBase, 2, 10); r != 0.05 {
+		t.Errorf("learning rate after warmup is %v, want 0.05", r)
+	}
+
+}
+
+// TestCloneSchedule checks that clones of schedules with a state are independent, and that other schedules
+// without a Clone method are shared.
+func TestCloneSchedule(t *testing.T) {
+
+	for _, s := range []Schedule{ReduceOnPlateau(0.5, 1, 0, 0), LinearWarmup(1, ReduceOnPlateau(0.5, 1, 0, 0))} {
+		observeMetric(s, 1)
+		c := cloneSchedule(s)
+		observeMetric(c, 2)
+		if r := s.Rate(scheduleBase, 2, 10); r != scheduleBase {
+			t.Errorf("%T: learning rate of schedule is %v after its clone was reduced", s, r)
+		}
+		if r := c.Rate(scheduleBase, 2, 10); r != 0.05 {
+			t.Errorf("%T: learning rate of clone is %v, want 0.05", s, r)
+		}
+	}
+
+	custom := &countingSchedule{}
+	c := cloneSchedule(custom)
+	observeMetric(c, 1)
+	if c == Schedule(custom) || custom.observed != 0 {
+		t.Error("schedule with a Clone method is shared with its clone")
+	}
+
+	// stateless built-in schedules are values
+	if s := StepDecay(0.5, 2); cloneSchedule(s) != s {
+		t.Error("step decay is not copied")
+	}
+
+}
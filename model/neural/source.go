@@ -0,0 +1,306 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+	// this repo internal import
+	mu "github.com/made2591/go-perceptron-go/util"
+
+)
+
+// PatternSource represents a stream of patterns read one at a time, so that data sets larger than memory
+// can be used: trainers read it again from the first pattern in each epoch (see MLPTrainSource).
+type PatternSource interface {
+
+	// Next returns the next pattern, false at the end of the source or if reading failed (see Err)
+	Next() (Pattern, bool)
+	// Reset starts reading again from the first pattern
+	Reset() error
+	// Err returns the error that stopped Next, nil if the end of the source was reached
+	Err() error
+
+}
+
+// RestorableSource represents a PatternSource whose state at the beginning of an epoch (e.g. of its random
+// generator) can be saved in checkpoints and restored, so that training reading it can be resumed exactly.
+// Sources of this package can be restored; training from other sources can be resumed only if they implement it.
+type RestorableSource interface {
+
+	PatternSource
+
+	// SourceState returns the state of the source, nil if reading it again from the first pattern is enough
+	SourceState() []uint64
+	// SetSourceState restores a state returned by SourceState
+	SetSourceState(state []uint64) error
+
+}
+
+// sliceSource reads patterns from memory.
+type sliceSource struct {
+	patterns []Pattern
+	i        int
+}
+
+// csvSource reads patterns from a CSV file, one record at a time.
+type csvSource struct {
+	path string
+	opt  CSVOptions
+	f    *os.File
+	r    *csvReader
+	err  error
+}
+
+// transformedSource returns patterns of a source with features transformed by a pipeline.
+type transformedSource struct {
+	PatternSource
+	p *Pipeline
+}
+
+// shuffleSource returns patterns of a source in random order, through a buffer.
+type shuffleSource struct {
+	src   PatternSource
+	size  int
+	state *mu.Source
+	rng   *rand.Rand
+	buf   []Pattern
+	full  bool
+}
+
+// #######################################################################################
+
+func init() {
+	// Output to stdout instead of the default stderr
+	log.SetOutput(os.Stdout)
+	// Only log the warning severity or above.
+	log.SetLevel(log.InfoLevel)
+}
+
+// SliceSource returns a source of patterns in memory, in order.
+func SliceSource(patterns []Pattern) PatternSource {
+	return &sliceSource{patterns: patterns}
+}
+
+func (s *sliceSource) Next() (Pattern, bool) {
+
+	if s.i >= len(s.patterns) {
+		return Pattern{}, false
+	}
+	s.i++
+	return s.patterns[s.i-1], true
+
+}
+
+func (s *sliceSource) Reset() error {
+	s.i = 0
+	return nil
+}
+
+func (s *sliceSource) Err() error {
+	return nil
+}
+
+func (s *sliceSource) SourceState() []uint64 {
+	return nil
+}
+
+func (s *sliceSource) SetSourceState(state []uint64) error {
+	return nil
+}
+
+// CSVSource returns a source reading patterns from the CSV file at filePath as they are needed, as specified
+// by optional CSV settings (see CSVOptions). Class names are encoded in order of appearance in the file, or by
+// CSVOptions.Labels: pass an encoder to know them. The file is open only while it is read: it is closed at its end
+// or on error, and opened again by Reset. The source implements io.Closer, to close it before its end.
+func CSVSource(filePath string, opts ...CSVOptions) (PatternSource, error) {
+
+	s := &csvSource{path: filePath}
+	if len(opts) > 0 {
+		s.opt = opts[0]
+	}
+	if s.opt.Labels == nil {
+		s.opt.Labels = &LabelEncoder{}
+	}
+
+	// check that the file can be read
+	if err := s.Reset(); err != nil {
+		return nil, err
+	}
+	return s, nil
+
+}
+
+func (s *csvSource) Next() (Pattern, bool) {
+
+	if s.r == nil {
+		return Pattern{}, false
+	}
+
+	p, err := s.r.read()
+	if err != nil {
+		if err != io.EOF {
+			s.err = err
+		}
+		s.Close()
+		return Pattern{}, false
+	}
+	return p, true
+
+}
+
+func (s *csvSource) Reset() error {
+
+	s.Close()
+	s.err = nil
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		s.err = err
+		return err
+	}
+	r, err := newCSVReader(f, s.opt)
+	if err != nil {
+		f.Close()
+		s.err = err
+		return err
+	}
+	s.f, s.r = f, r
+	return nil
+
+}
+
+func (s *csvSource) Err() error {
+	return s.err
+}
+
+func (s *csvSource) SourceState() []uint64 {
+	return nil
+}
+
+func (s *csvSource) SetSourceState(state []uint64) error {
+	return nil
+}
+
+// Close closes the file, if open: Next returns false until Reset.
+func (s *csvSource) Close() error {
+
+	s.r = nil
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+
+}
+
+func (s *transformedSource) Next() (Pattern, bool) {
+
+	p, ok := s.PatternSource.Next()
+	if ok {
+		p.Features = s.p.Transform(p.Features)
+	}
+	return p, ok
+
+}
+
+// Shuffled returns a source with patterns of src in random order: patterns are read in a buffer of size patterns,
+// and each one returned is drawn from it, using a generator seeded from rng (nil uses current time).
+// Patterns are fully shuffled if size is at least their number; each Reset gives a new order.
+// The state of the generator is saved in checkpoints, so that training can be resumed with the same orders.
+func Shuffled(src PatternSource, size int, rng *rand.Rand) PatternSource {
+
+	if size < 1 {
+		size = 1
+	}
+	state := mu.NewSource(mu.Rand(rng).Int63())
+	return &shuffleSource{src: src, size: size, state: state, rng: rand.New(state)}
+
+}
+
+func (s *shuffleSource) Next() (Pattern, bool) {
+
+	// fill the buffer at the beginning
+	if !s.full {
+		for len(s.buf) < s.size {
+			p, ok := s.src.Next()
+			if !ok {
+				break
+			}
+			s.buf = append(s.buf, p)
+		}
+		s.full = true
+	}
+	if len(s.buf) == 0 {
+		return Pattern{}, false
+	}
+
+	// draw a pattern, replacing it with the next one of src
+	i := s.rng.Intn(len(s.buf))
+	p := s.buf[i]
+	if n, ok := s.src.Next(); ok {
+		s.buf[i] = n
+	} else {
+		last := len(s.buf) - 1
+		s.buf[i] = s.buf[last]
+		s.buf = s.buf[:last]
+	}
+	return p, true
+
+}
+
+func (s *shuffleSource) Reset() error {
+
+	s.buf, s.full = s.buf[:0], false
+	return s.src.Reset()
+
+}
+
+func (s *shuffleSource) Err() error {
+	return s.src.Err()
+}
+
+// sourceState returns the state of src, to save in checkpoints.
+// It returns an error if src cannot be restored (see RestorableSource).
+func sourceState(src PatternSource) ([]uint64, error) {
+
+	switch s := src.(type) {
+	case *transformedSource:
+		return sourceState(s.PatternSource)
+	case *shuffleSource:
+		inner, err := sourceState(s.src)
+		return append([]uint64{s.state.State()}, inner...), err
+	case RestorableSource:
+		return s.SourceState(), nil
+	}
+	return nil, fmt.Errorf("neural: source %T cannot be restored (see RestorableSource)", src)
+
+}
+
+// setSourceState restores in src a state returned by sourceState.
+// It returns an error if src cannot be restored, or state is not one of src.
+func setSourceState(src PatternSource, state []uint64) error {
+
+	switch s := src.(type) {
+	case *transformedSource:
+		return setSourceState(s.PatternSource, state)
+	case *shuffleSource:
+		if len(state) == 0 {
+			return fmt.Errorf("neural: no state of shuffled source to restore")
+		}
+		s.state.SetState(state[0])
+		return setSourceState(s.src, state[1:])
+	case RestorableSource:
+		return s.SetSourceState(state)
+	}
+	return fmt.Errorf("neural: source %T cannot be restored (see RestorableSource)", src)
+
+}
@@ -0,0 +1,719 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+	// this repo internal import
+	mu "github.com/made2591/go-perceptron-go/util"
+
+)
+
+const (
+
+	// FullBatch as TrainOptions.BatchSize accumulates derivatives over the whole training set before each update
+	FullBatch = -1
+
+)
+
+// ErrInterrupted is matched (with errors.Is) by errors returned by trainers stopped because their context is done.
+var ErrInterrupted = errors.New("neural: training interrupted")
+
+// InterruptedError is returned by trainers stopped because their context is done.
+// The model keeps the weights reached, and the returned history holds the epochs completed: resuming from
+// the last checkpoint restarts the interrupted epoch from its beginning.
+type InterruptedError struct {
+
+	// Epoch represents epoch interrupted, from 0
+	Epoch int
+	// Step represents number of updates done
+	Step int
+	// Err represents the error of the context
+	Err error
+
+}
+
+// TrainOptions represents optional settings of training algorithms.
+// The zero value trains online, one pattern at a time, in the order given.
+type TrainOptions struct {
+
+	// BatchSize represents number of patterns whose derivatives are accumulated before each update:
+	// 0 or 1 is online training, FullBatch is the whole training set
+	BatchSize int
+	// Shuffle represents if patterns are shuffled at the beginning of each epoch
+	Shuffle bool
+	// Seed represents the seed of the shuffling, so that training is reproducible (0 uses Rand)
+	Seed int64
+	// Rand represents the generator of the experiment, used to seed the shuffling if Seed is 0
+	// (nil uses current time)
+	Rand *rand.Rand
+	// Schedule represents the policy to change the learning rate of the model during training (nil is constant):
+	// clones of the options share schedules that are not built-in, unless they have a Clone() Schedule method
+	Schedule Schedule
+	// Workers represents number of goroutines sharing the patterns of each batch, on replicas of the network
+	// (0 or 1 uses only the calling one): the network trained is the same, bit for bit, whatever their number.
+	// It has no effect on online training and on recurrent networks
+	Workers int
+
+	// Validation represents patterns used to monitor training, never used to update weights
+	Validation []Pattern
+	// ValidationSplit represents fraction of training patterns held out for validation, if Validation is not given:
+	// patterns are held out evenly along each class, so that validation keeps the proportions of classes even if
+	// patterns are sorted by class, and always the same ones, so that training can be resumed
+	ValidationSplit float64
+	// EarlyStopping represents the criterion to stop training before the number of epochs (nil never stops)
+	EarlyStopping *EarlyStopping
+
+	// Callbacks represents hooks called during training, in order. If nil, trainers log the progress of training
+	// as they always did: set an empty slice to silence them
+	Callbacks []Callback
+
+	// Resume represents a checkpoint to continue training from (nil starts a new training): model, epoch, randomness
+	// and history are restored from it, other settings must be the ones of the training that wrote it.
+	// Training restarts from the epoch following the checkpoint, so updates of an interrupted epoch are done again
+	Resume *Checkpoint
+
+}
+
+// EarlyStopping represents a criterion to stop training when the monitored loss, validation loss
+// if available or training loss otherwise, stops improving.
+type EarlyStopping struct {
+
+	// Patience represents number of epochs without improvement before stopping
+	Patience int
+	// MinDelta represents minimum decrease of the loss counted as improvement
+	MinDelta float64
+	// RestoreBest represents if the weights of the best epoch are restored when training ends
+	RestoreBest bool
+
+}
+
+// EpochStats represents losses and accuracies reached in an epoch of training.
+type EpochStats struct {
+
+	// Epoch represents number of epoch, from 0
+	Epoch int `json:"epoch"`
+	// LearningRate represents (scheduled) learning rate at the end of epoch
+	LearningRate float64 `json:"learning_rate"`
+	// TrainLoss represents mean error of patterns in training set, computed while training
+	TrainLoss float64 `json:"train_loss"`
+	// TrainAccuracy represents fraction of correct predictions in training set, computed while training
+	TrainAccuracy float64 `json:"train_accuracy"`
+	// Validated represents if validation loss and accuracy are available
+	Validated bool `json:"validated"`
+	// ValidationLoss represents mean error of patterns in validation set
+	ValidationLoss float64 `json:"validation_loss"`
+	// ValidationAccuracy represents fraction of correct predictions in validation set
+	ValidationAccuracy float64 `json:"validation_accuracy"`
+
+}
+
+// History represents the evolution of a training.
+type History struct {
+
+	// Epochs represents statistics of each epoch done
+	Epochs []EpochStats `json:"epochs"`
+	// BestEpoch represents epoch with lowest monitored loss
+	BestEpoch int `json:"best_epoch"`
+	// Stopped represents if training was stopped early
+	Stopped bool `json:"stopped"`
+
+}
+
+// networkTask describes how a MultiLayerNetwork is trained and evaluated on patterns.
+type networkTask struct {
+
+	// method represents name of trainer, for logging
+	method string
+	// recurrent represents if hidden layer is copied to context (Elman network)
+	recurrent bool
+	// target returns expected output of network for pattern
+	target func(p *Pattern) []float64
+	// correct returns fraction of correct predictions in output o of network for pattern
+	correct func(p *Pattern, o []float64) float64
+	// callbacks represents default callbacks of trainer, used if none is given in training settings
+	callbacks []Callback
+
+}
+
+// epochReader reads batches of training patterns, epoch by epoch.
+type epochReader interface {
+
+	// begin starts an epoch, shuffling patterns with rng if required
+	begin(rng *rand.Rand) error
+	// next returns the next batch of patterns of the epoch, none at its end
+	next() ([]*Pattern, error)
+	// patterns returns training patterns, nil if they are not in memory
+	patterns() []Pattern
+	// state returns the state of the reader at the end of an epoch, to save in checkpoints
+	state() ([]uint64, error)
+	// restore restores a state returned by state, before the first epoch
+	restore(state []uint64) error
+
+}
+
+// sliceReader reads batches of patterns in memory, as specified by training settings.
+type sliceReader struct {
+	train []Pattern
+	opt   TrainOptions
+	order [][]int
+	i     int
+	batch []*Pattern
+}
+
+// sourceReader reads batches of size patterns (0 is 1, FullBatch is all of them) from a source.
+type sourceReader struct {
+	src   PatternSource
+	size  int
+	buf   []Pattern
+	batch []*Pattern
+}
+
+// monitor keeps track of the best epoch of a training, for early stopping.
+type monitor struct {
+	es   *EarlyStopping
+	best float64
+	wait int
+}
+
+// #######################################################################################
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("%v at epoch %d, step %d: %v", ErrInterrupted, e.Epoch, e.Step, e.Err)
+}
+
+// Unwrap returns the error of the context, so that errors.Is matches context.Canceled or context.DeadlineExceeded.
+func (e *InterruptedError) Unwrap() error { return e.Err }
+
+// Is reports if target is ErrInterrupted.
+func (e *InterruptedError) Is(target error) bool { return target == ErrInterrupted }
+
+// interrupted returns an InterruptedError if ctx is done, nil otherwise.
+func interrupted(ctx context.Context, s *TrainState) error {
+
+	if err := ctx.Err(); err != nil {
+		return &InterruptedError{Epoch: s.Epoch, Step: s.Step, Err: err}
+	}
+	return nil
+
+}
+
+// logTrainError logs err, returned by a trainer without a context, if not nil.
+func logTrainError(method string, err error) {
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"level":  "error",
+			"place":  "training",
+			"method": method,
+			"error":  err,
+		}).Error("Training failed.")
+	}
+
+}
+
+// trainOptions returns the first of optional training settings, or the zero value.
+func trainOptions(opts []TrainOptions) TrainOptions {
+
+	if len(opts) == 0 {
+		return TrainOptions{}
+	}
+	return opts[0]
+
+}
+
+// random returns the source of randomness used by training, whose state is saved in checkpoints.
+func (opts TrainOptions) random() *mu.Source {
+
+	if opts.Seed != 0 {
+		return mu.NewSource(opts.Seed)
+	}
+	if opts.Rand != nil {
+		return mu.NewSource(opts.Rand.Int63())
+	}
+	return mu.NewSource(time.Now().UTC().UnixNano())
+
+}
+
+// batches returns indexes of patterns, in training order, grouped in batches.
+// [n:int] number of patterns, [rng:rand.Rand] source of shuffling
+func (opts TrainOptions) batches(n int, rng *rand.Rand) [][]int {
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	if opts.Shuffle {
+		rng.Shuffle(n, func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+
+	size := opts.BatchSize
+	if size == FullBatch || size > n {
+		size = n
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	var bs [][]int
+	for i := 0; i < n; i += size {
+		e := i + size
+		if e > n {
+			e = n
+		}
+		bs = append(bs, order[i:e])
+	}
+
+	return bs
+
+}
+
+// begin starts an epoch, shuffling order of patterns with rng if required.
+func (r *sliceReader) begin(rng *rand.Rand) error {
+
+	r.order, r.i = r.opt.batches(len(r.train), rng), 0
+	return nil
+
+}
+
+// next returns the next batch of patterns of the epoch, none at its end.
+func (r *sliceReader) next() ([]*Pattern, error) {
+
+	if r.i >= len(r.order) {
+		return nil, nil
+	}
+	r.batch = r.batch[:0]
+	for _, p := range r.order[r.i] {
+		r.batch = append(r.batch, &r.train[p])
+	}
+	r.i++
+	return r.batch, nil
+
+}
+
+func (r *sliceReader) patterns() []Pattern {
+	return r.train
+}
+
+// state is always nil: the order of patterns depends only on the generator of training.
+func (r *sliceReader) state() ([]uint64, error) {
+	return nil, nil
+}
+
+func (r *sliceReader) restore(state []uint64) error {
+	return nil
+}
+
+// begin starts an epoch, reading the source from the beginning.
+func (r *sourceReader) begin(rng *rand.Rand) error {
+	return r.src.Reset()
+}
+
+// next reads the next batch of patterns of the epoch, none at its end.
+func (r *sourceReader) next() ([]*Pattern, error) {
+
+	r.buf, r.batch = r.buf[:0], r.batch[:0]
+	for r.size == FullBatch || len(r.buf) < r.size {
+		p, ok := r.src.Next()
+		if !ok {
+			break
+		}
+		r.buf = append(r.buf, p)
+	}
+	if len(r.buf) == 0 {
+		return nil, r.src.Err()
+	}
+
+	for i := range r.buf {
+		r.batch = append(r.batch, &r.buf[i])
+	}
+	return r.batch, nil
+
+}
+
+func (r *sourceReader) patterns() []Pattern {
+	return nil
+}
+
+// state returns the state of the source, see RestorableSource.
+func (r *sourceReader) state() ([]uint64, error) {
+	return sourceState(r.src)
+}
+
+func (r *sourceReader) restore(state []uint64) error {
+	return setSourceState(r.src, state)
+}
+
+// callbacks returns callbacks of training, or defaults if not specified.
+func (opts TrainOptions) callbacks(defaults ...Callback) callbacks {
+
+	if opts.Callbacks == nil {
+		return defaults
+	}
+	return opts.Callbacks
+
+}
+
+// readers returns the reader of training patterns and validation patterns, as specified by training settings.
+// If p is not nil, it is fitted on training patterns and both are transformed.
+func (opts TrainOptions) readers(patterns []Pattern, p *Pipeline) (epochReader, []Pattern, error) {
+
+	train, validation := opts.trainingSets(patterns)
+	if p != nil {
+		if err := p.Fit(train); err != nil {
+			return nil, nil, err
+		}
+		train, validation = p.TransformPatterns(train), p.TransformPatterns(validation)
+	}
+	return &sliceReader{train: train, opt: opts}, validation, nil
+
+}
+
+// sourceReader returns the reader of training patterns of src, as specified by training settings.
+func (opts TrainOptions) sourceReader(src PatternSource) epochReader {
+
+	size := opts.BatchSize
+	if size < 1 && size != FullBatch {
+		size = 1
+	}
+	return &sourceReader{src: src, size: size}
+
+}
+
+// trainingSets returns patterns used to train and to validate, as specified by training settings.
+func (opts TrainOptions) trainingSets(patterns []Pattern) (train []Pattern, validation []Pattern) {
+
+	if opts.Validation != nil {
+		return patterns, opts.Validation
+	}
+	if opts.ValidationSplit > 0 && opts.ValidationSplit < 1 {
+		return holdOut(patterns, opts.ValidationSplit)
+	}
+	return patterns, nil
+
+}
+
+// holdOut splits patterns in train and validation, holding out fraction of patterns (see ValidationSplit).
+// Patterns are grouped by class (see ClassGroups), and every 1 / fraction pattern of the groups is held out:
+// each class keeps its proportion, up to a pattern. Both sets keep the order of patterns.
+func holdOut(patterns []Pattern, fraction float64) (train []Pattern, validation []Pattern) {
+
+	// the same number of patterns held out as a plain split, spread evenly along groups
+	n, held := len(patterns), make([]bool, len(patterns))
+	h, pos := int(float64(n)*fraction), 0
+	for _, g := range ClassGroups(patterns) {
+		for _, i := range g {
+			held[i] = (pos+1)*h/n > pos*h/n
+			pos++
+		}
+	}
+
+	for i := range patterns {
+		if held[i] {
+			validation = append(validation, patterns[i])
+		} else {
+			train = append(train, patterns[i])
+		}
+	}
+	return train, validation
+
+}
+
+// observe records the monitored loss of epoch in history.
+// It returns true if epoch is the best one so far, and if training has to stop.
+func (m *monitor) observe(h *History, epoch int, loss float64) (improved bool, stop bool) {
+
+	var minDelta float64
+	if m.es != nil {
+		minDelta = m.es.MinDelta
+	}
+
+	if epoch == 0 || loss < m.best-minDelta {
+		m.best, m.wait, h.BestEpoch = loss, 0, epoch
+		return true, false
+	}
+
+	m.wait++
+	return false, m.es != nil && m.wait >= m.es.Patience
+
+}
+
+// monitored returns the loss monitored by early stopping and schedules.
+func (e EpochStats) monitored() float64 {
+
+	if e.Validated {
+		return e.ValidationLoss
+	}
+	return e.TrainLoss
+
+}
+
+// trainNetwork trains a MultiLayerNetwork on task for at most epochs epochs, checking ctx after each batch.
+// It returns the history of training, and an InterruptedError if ctx is done before the end.
+// Training patterns are read from data in each epoch, validation ones are used to monitor training.
+func trainNetwork(ctx context.Context, mlp *MultiLayerNetwork, data epochReader, validation []Pattern, epochs int, opt TrainOptions, task networkTask) (h History, err error) {
+
+	src := opt.random()
+	rng := rand.New(src)
+	m := monitor{es: opt.EarlyStopping}
+	cs := opt.callbacks(task.callbacks...)
+	s := &TrainState{Method: task.method, Epochs: epochs, History: &h, Network: mlp, Patterns: data.patterns(), src: src, data: data}
+	var best networkWeights
+	s.snapshot = func(c *Checkpoint) {
+		c.network = mlp.snapshot()
+		if best.Weights != nil {
+			b := best.clone()
+			c.best = &b
+		}
+	}
+
+	// continue from checkpoint
+	start := 0
+	if c := opt.Resume; c != nil {
+		if c.network == nil {
+			return h, fmt.Errorf("neural: cannot resume %s from a checkpoint of %s", task.method, c.Method)
+		}
+		if err = mlp.fromSnapshot(c.network); err != nil {
+			return
+		}
+		if c.best != nil {
+			best = c.best.clone()
+		}
+		if start, err = c.resume(s, &m, opt.Schedule); err != nil {
+			return
+		}
+	}
+
+	g := NewGradients(mlp)
+	options := []int{}
+	if task.recurrent {
+		options = append(options, 1)
+	}
+
+	// replicas of network for data parallel training
+	var dp *dataParallel
+	if opt.Workers > 1 && !task.recurrent {
+		dp = newDataParallel(mlp, opt.Workers)
+	}
+
+	cs.trainBegin(s)
+
+	// for fixed number of epochs
+	for epoch := start; epoch < epochs && !s.Stop; epoch++ {
+
+		s.Epoch = epoch
+		stats := EpochStats{Epoch: epoch}
+		seen := 0
+		cs.epochBegin(s)
+
+		// for each batch of patterns in training set
+		if err = data.begin(rng); err != nil {
+			cs.trainEnd(s)
+			return
+		}
+		for b := 0; ; b++ {
+
+			var batch []*Pattern
+			if batch, err = data.next(); err != nil {
+				cs.trainEnd(s)
+				return
+			}
+			if len(batch) == 0 {
+				break
+			}
+
+			g.Reset()
+
+			if task.recurrent || len(batch) == 1 {
+				// for each pattern in batch, back propagation
+				for _, p := range batch {
+					ComputeGradients(mlp, p, task.target(p), g, options...)
+					stats.TrainAccuracy += task.correct(p, mlp.NeuralLayers[len(mlp.NeuralLayers)-1].a)
+				}
+			} else {
+				// back propagation of the whole batch at once
+				ts := make([][]float64, len(batch))
+				for i, p := range batch {
+					ts[i] = task.target(p)
+				}
+				var y []float64
+				if dp != nil {
+					y = dp.computeBatchGradients(mlp, batch, ts, g)
+				} else {
+					y = ComputeBatchGradients(mlp, batch, ts, g)
+				}
+				m := mlp.NeuralLayers[len(mlp.NeuralLayers)-1].Length
+				for i, p := range batch {
+					stats.TrainAccuracy += task.correct(p, y[i*m:(i+1)*m])
+				}
+			}
+
+			// update weights with mean of derivatives in batch, using scheduled learning rate
+			ApplyGradients(mlp, g, scheduledRate(opt.Schedule, mlp.L_rate, epoch, s.Step))
+			stats.TrainLoss += g.Loss
+			seen += len(batch)
+
+			s.Batch, s.BatchLoss = b, g.Loss / float64(len(batch))
+			s.Step++
+			cs.batchEnd(s)
+			if s.Stop {
+				break
+			}
+
+			// stop cleanly if context is done
+			if err = interrupted(ctx, s); err != nil {
+				cs.trainEnd(s)
+				return
+			}
+
+		}
+
+		stats.TrainLoss /= float64(seen)
+		stats.TrainAccuracy /= float64(seen)
+		stats.LearningRate = scheduledRate(opt.Schedule, mlp.L_rate, epoch, s.Step)
+
+		// evaluate on validation set
+		if len(validation) > 0 {
+			stats.Validated = true
+			for i := range validation {
+				o := Execute(mlp, &validation[i], options...)
+				stats.ValidationLoss += mlp.lossFunc().Loss(o, task.target(&validation[i]))
+				stats.ValidationAccuracy += task.correct(&validation[i], o)
+			}
+			stats.ValidationLoss /= float64(len(validation))
+			stats.ValidationAccuracy /= float64(len(validation))
+		}
+
+		h.Epochs = append(h.Epochs, stats)
+		observeMetric(opt.Schedule, stats.monitored())
+
+		// check early stopping
+		improved, stop := m.observe(&h, epoch, stats.monitored())
+		if improved && opt.EarlyStopping != nil && opt.EarlyStopping.RestoreBest {
+			best = copyNetworkWeights(mlp)
+		}
+		if stop {
+			h.Stopped, s.Stop = true, true
+		}
+
+		s.Stats = stats
+		cs.epochEnd(s)
+
+	}
+
+	// restore weights of best epoch
+	if best.Weights != nil && h.BestEpoch != len(h.Epochs)-1 {
+		best.restore(mlp)
+	}
+
+	cs.trainEnd(s)
+
+	return
+
+}
+
+// networkWeights represents a copy of weights and biases of each layer of a MultiLayerNetwork.
+type networkWeights struct {
+	Weights [][][]float64 `json:"weights"`
+	Biases  [][]float64   `json:"biases"`
+}
+
+// copyNetworkWeights takes a copy of weights and biases of each layer of mlp.
+func copyNetworkWeights(mlp *MultiLayerNetwork) (nw networkWeights) {
+
+	nw.Weights = make([][][]float64, len(mlp.NeuralLayers))
+	nw.Biases = make([][]float64, len(mlp.NeuralLayers))
+	for k, l := range mlp.NeuralLayers {
+		nw.Weights[k] = make([][]float64, l.Length)
+		nw.Biases[k] = make([]float64, l.Length)
+		for i, n := range l.NeuronUnits {
+			nw.Weights[k][i] = append([]float64(nil), n.Weights...)
+			nw.Biases[k][i] = n.Bias
+		}
+	}
+	return
+
+}
+
+// clone returns a deep copy of nw.
+func (nw networkWeights) clone() (c networkWeights) {
+
+	c.Weights = make([][][]float64, len(nw.Weights))
+	c.Biases = make([][]float64, len(nw.Biases))
+	for k := range nw.Weights {
+		c.Weights[k] = make([][]float64, len(nw.Weights[k]))
+		for i := range nw.Weights[k] {
+			c.Weights[k][i] = append([]float64(nil), nw.Weights[k][i]...)
+		}
+		c.Biases[k] = append([]float64(nil), nw.Biases[k]...)
+	}
+	return
+
+}
+
+// restore copies back weights and biases into mlp.
+func (nw networkWeights) restore(mlp *MultiLayerNetwork) {
+
+	for k := range mlp.NeuralLayers {
+		for i := range mlp.NeuralLayers[k].NeuronUnits {
+			copy(mlp.NeuralLayers[k].NeuronUnits[i].Weights, nw.Weights[k][i])
+			mlp.NeuralLayers[k].NeuronUnits[i].Bias = nw.Biases[k][i]
+		}
+	}
+
+}
+
+// classifierTask returns the task of classifying patterns by SingleExpectation into classes, one per output unit.
+func classifierTask(classes int) networkTask {
+
+	return networkTask{
+		method:    "MLPTrain",
+		callbacks: []Callback{LogCallback{}},
+		target: func(p *Pattern) []float64 {
+			// setup desired output for specific class of pattern focused
+			o := make([]float64, classes)
+			o[int(p.SingleExpectation)] = 1.0
+			return o
+		},
+		correct: func(p *Pattern, o []float64) float64 {
+			// index of max output
+			if _, i := mu.MaxInSlice(o); float64(i) == p.SingleExpectation {
+				return 1.0
+			}
+			return 0.0
+		},
+	}
+
+}
+
+// sequenceTask returns the task of predicting MultipleExpectation with a recurrent network, one binary value per output unit.
+func sequenceTask() networkTask {
+
+	return networkTask{
+		method:    "ElmanTrain",
+		recurrent: true,
+		callbacks: []Callback{LogCallback{}, ElmanSumCallback{Every: 100}},
+		target:    func(p *Pattern) []float64 { return p.MultipleExpectation },
+		correct: func(p *Pattern, o []float64) float64 {
+			// fraction of rounded outputs equal to expected ones
+			c := 0.0
+			for i := range o {
+				if math.Abs(mu.Round(o[i], .5, 0)-p.MultipleExpectation[i]) < 0.5 {
+					c++
+				}
+			}
+			return c / float64(len(o))
+		},
+	}
+
+}
@@ -0,0 +1,152 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+)
+
+// #######################################################################################
+
+// TestValidationSplitClasses checks that ValidationSplit holds out every class of patterns sorted by class.
+func TestValidationSplitClasses(t *testing.T) {
+
+	patterns := make([]Pattern, 30)
+	for i := range patterns {
+		patterns[i] = Pattern{Features: []float64{float64(i)}, SingleExpectation: float64(i / 10)}
+	}
+
+	train, validation := TrainOptions{ValidationSplit: 0.2}.trainingSets(patterns)
+	if len(train) != 24 || len(validation) != 6 {
+		t.Fatalf("split of 30 patterns is %d / %d, want 24 / 6", len(train), len(validation))
+	}
+	counts := map[float64]int{}
+	for _, p := range validation {
+		counts[p.SingleExpectation]++
+	}
+	for c := 0.0; c < 3; c++ {
+		if counts[c] != 2 {
+			t.Errorf("validation holds %d patterns of class %v, want 2", counts[c], c)
+		}
+	}
+
+	// same split each time, as resume requires
+	_, again := TrainOptions{ValidationSplit: 0.2}.trainingSets(patterns)
+	for i := range validation {
+		if validation[i].Features[0] != again[i].Features[0] {
+			t.Fatalf("validation split changes between calls")
+		}
+	}
+
+}
+
+// TestWorkersSameWeights checks that data parallel training reaches the same weights as a single worker, bit for bit.
+func TestWorkersSameWeights(t *testing.T) {
+
+	patterns, err, mapped := LoadPatternsFromCSVFile(sonarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the last batch of 208 patterns holds 1 pattern with 23, and the output layer fewer neurons than workers
+	for _, batchSize := range []int{23, FullBatch} {
+
+		var ws [][]NeuralLayer
+		for _, workers := range []int{1, 4} {
+			mlp := PrepareMLPNet([]int{60, 10, 7, 2}, 0.1, Sigmoid, rand.New(rand.NewSource(5)))
+			MLPTrain(&mlp, patterns, mapped, 5, TrainOptions{BatchSize: batchSize, Shuffle: true, Seed: 7, Workers: workers, Callbacks: []Callback{}})
+			ws = append(ws, mlp.NeuralLayers)
+		}
+
+		for k := 1; k < len(ws[0]); k++ {
+			for i := range ws[0][k].W {
+				if ws[0][k].W[i] != ws[1][k].W[i] {
+					t.Fatalf("batch %d: weight %d of layer %d is %v with 1 worker, %v with 4", batchSize, i, k, ws[0][k].W[i], ws[1][k].W[i])
+				}
+			}
+			for i := range ws[0][k].B {
+				if ws[0][k].B[i] != ws[1][k].B[i] {
+					t.Fatalf("batch %d: bias %d of layer %d is %v with 1 worker, %v with 4", batchSize, i, k, ws[0][k].B[i], ws[1][k].B[i])
+				}
+			}
+		}
+
+	}
+
+}
+
+// TestTrainContextCancelled checks that trainers stop after the batch during which their context is cancelled,
+// returning an InterruptedError with the epochs completed.
+func TestTrainContextCancelled(t *testing.T) {
+
+	trainers := map[string]func(ctx context.Context, opt TrainOptions) (History, error){
+		"MLPTrainContext": func(ctx context.Context, opt TrainOptions) (History, error) {
+			mlp := PrepareMLPNet([]int{2, 4, 2}, 0.3, Sigmoid, rand.New(rand.NewSource(3)))
+			return MLPTrainContext(ctx, &mlp, sourcePatterns(), []string{"0", "1"}, 10, opt)
+		},
+		"TrainNeuronContext": func(ctx context.Context, opt TrainOptions) (History, error) {
+			return TrainNeuronContext(ctx, &NeuronUnit{Lrate: 0.1}, sourcePatterns(), 10, 1, opt)
+		},
+	}
+
+	for name, train := range trainers {
+
+		// cancel during the fourth batch of the third epoch
+		ctx, cancel := context.WithCancel(context.Background())
+		batches := 0
+		stop := CallbackFuncs{BatchEnd: func(s *TrainState) {
+			batches++
+			if s.Epoch == 2 && s.Batch == 3 {
+				cancel()
+			}
+		}}
+		h, err := train(ctx, TrainOptions{Callbacks: []Callback{stop}})
+		cancel()
+
+		var ie *InterruptedError
+		if !errors.Is(err, ErrInterrupted) || !errors.Is(err, context.Canceled) || !errors.As(err, &ie) {
+			t.Fatalf("%s: error is %v, want an InterruptedError of a cancelled context", name, err)
+		}
+		if ie.Epoch != 2 || ie.Step != 2*40+4 || batches != 2*40+4 {
+			t.Errorf("%s: interrupted at epoch %d, step %d after %d batches, want epoch 2, step 84", name, ie.Epoch, ie.Step, batches)
+		}
+		if len(h.Epochs) != 2 {
+			t.Errorf("%s: history holds %d epochs, want the 2 completed", name, len(h.Epochs))
+		}
+
+	}
+
+}
+
+// TestCloneTrainOptions checks that clones of training settings start a new training with their own seed.
+func TestCloneTrainOptions(t *testing.T) {
+
+	opts := []TrainOptions{{Rand: rand.New(rand.NewSource(1)), Resume: &Checkpoint{Method: "MLPTrain", Epoch: 3}}}
+	a, b := cloneTrainOptions(opts), cloneTrainOptions(opts)
+	if a[0].Resume != nil || b[0].Resume != nil {
+		t.Error("clones of training settings resume the training of the model cloned")
+	}
+	if a[0].Rand != nil || a[0].Seed == 0 || a[0].Seed == b[0].Seed {
+		t.Errorf("clones of training settings have seeds %d and %d, want different ones drawn from Rand", a[0].Seed, b[0].Seed)
+	}
+	if opts[0].Resume == nil {
+		t.Error("settings cloned lose their checkpoint")
+	}
+
+}
+
+// TestTrainNeuronNoPatterns checks that training a neuron on no patterns returns an error.
+func TestTrainNeuronNoPatterns(t *testing.T) {
+
+	for _, patterns := range [][]Pattern{nil, {}} {
+		if _, err := TrainNeuronContext(context.Background(), &NeuronUnit{Lrate: 0.1}, patterns, 5, 1); err == nil {
+			t.Errorf("training on %d patterns has no error", len(patterns))
+		}
+	}
+
+}
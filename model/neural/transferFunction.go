@@ -3,50 +3,381 @@ package neural
 
 import (
 
+	// sys import
+	"errors"
+	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 )
 
-// define transfer function desiderata
-type transferFunction func(float64) float64
+// Activation represents a named transfer function together with its derivative.
+type Activation interface {
+
+	// Name returns the name used to register and serialize the activation
+	Name() string
+	// Forward computes the output of the activation for pre-activation value z
+	Forward(z float64) float64
+	// Derivative computes the derivative of the activation in z, given its output y = Forward(z)
+	Derivative(z float64, y float64) float64
+
+}
+
+// VectorActivation represents an activation whose outputs depend on the whole layer, like softmax.
+type VectorActivation interface {
+
+	Activation
+
+	// ForwardVector computes outputs y of the whole layer given pre-activation values z
+	ForwardVector(z []float64, y []float64)
+	// BackwardVector computes g = J^T d, where J is the jacobian of the activation in z with outputs y
+	BackwardVector(z []float64, y []float64, d []float64, g []float64)
+
+}
+
+// built-in activations
+var (
+
+	// Heaviside is the step function. Its derivative is taken as 1 (straight-through).
+	Heaviside Activation = heaviside{}
+	// Sigmoid is the logistic function 1 / (1 + e^-z)
+	Sigmoid Activation = sigmoid{}
+	// Tanh is the hyperbolic tangent
+	Tanh Activation = hyperbolic{}
+	// ReLU is the rectified linear unit max(0, z)
+	ReLU Activation = relu{}
+	// Softplus is the smooth approximation of ReLU log(1 + e^z)
+	Softplus Activation = softplus{}
+	// Linear is the identity function
+	Linear Activation = linear{}
+	// Softmax normalizes the layer outputs into a probability distribution
+	Softmax Activation = softmax{}
+
+)
+
+const (
+
+	// DefaultLeakyReLUAlpha is the slope used by the registered "leaky_relu" activation for z < 0
+	DefaultLeakyReLUAlpha = 0.01
+	// DefaultELUAlpha is the saturation used by the registered "elu" activation for z < 0
+	DefaultELUAlpha = 1.0
+
+)
+
+var (
+
+	// activationsMu guards activations registry
+	activationsMu sync.RWMutex
+	// activations is the registry of activations by name
+	activations = map[string]Activation{}
+	// parametricActivations builds activations with a parameter, named as "name(parameter)"
+	parametricActivations = map[string]func(float64) Activation{
+		"leaky_relu": LeakyReLU,
+		"elu":        ELU,
+	}
+
+)
+
+// #######################################################################################
+
+func init() {
+
+	for _, a := range []Activation{Heaviside, Sigmoid, Tanh, ReLU, LeakyReLU(DefaultLeakyReLUAlpha),
+		ELU(DefaultELUAlpha), Softplus, Linear, Softmax} {
+		if err := RegisterActivation(a); err != nil {
+			panic(err)
+		}
+	}
+
+}
+
+// RegisterActivation adds an activation to the registry, using its name as key.
+// It returns an error if the name is empty or already registered.
+func RegisterActivation(a Activation) error {
+
+	activationsMu.Lock()
+	defer activationsMu.Unlock()
+
+	if a == nil || a.Name() == "" {
+		return errors.New("neural: activation must have a name")
+	}
+	if _, ok := activations[a.Name()]; ok {
+		return fmt.Errorf("neural: activation %q already registered", a.Name())
+	}
+	activations[a.Name()] = a
+
+	return nil
+
+}
+
+// ActivationByName looks for a registered activation.
+// Parametric built-ins can be requested with their parameter, like "leaky_relu(0.2)".
+// It returns an error if no activation matches the name.
+func ActivationByName(name string) (Activation, error) {
+
+	activationsMu.RLock()
+	a, ok := activations[name]
+	activationsMu.RUnlock()
+	if ok {
+		return a, nil
+	}
+
+	// look for a parametric activation
+	if i := strings.IndexByte(name, '('); i > 0 && strings.HasSuffix(name, ")") {
+		if build, ok := parametricActivations[name[:i]]; ok {
+			if p, err := strconv.ParseFloat(name[i+1:len(name)-1], 64); err == nil {
+				return build(p), nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("neural: unknown activation %q", name)
+
+}
+
+// ActivationNames returns the sorted names of registered activations.
+func ActivationNames() []string {
+
+	activationsMu.RLock()
+	defer activationsMu.RUnlock()
+
+	names := make([]string, 0, len(activations))
+	for name := range activations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+
+}
+
+// LeakyReLU returns a rectified linear unit with slope alpha for z < 0.
+func LeakyReLU(alpha float64) Activation {
+	return leakyReLU{alpha: alpha}
+}
+
+// ELU returns an exponential linear unit saturating to -alpha for z < 0.
+func ELU(alpha float64) Activation {
+	return elu{alpha: alpha}
+}
+
+// parametricName returns the name of a parametric activation, omitting the default parameter.
+func parametricName(name string, p float64, def float64) string {
+	if p == def {
+		return name
+	}
+	return name + "(" + strconv.FormatFloat(p, 'g', -1, 64) + ")"
+}
 
 // different type of transfer function
 
-func HeavysideTransfer(d float64) float64 {
+type heaviside struct{}
+
+func (heaviside) Name() string { return "heaviside" }
+
+func (heaviside) Forward(z float64) float64 {
+
+	if z >= 0.0 {
+		return 1.0
+	}
+	return 0.0
+
+}
+
+func (heaviside) Derivative(z float64, y float64) float64 { return 1.0 }
+
+type sigmoid struct{}
+
+func (sigmoid) Name() string { return "sigmoid" }
+
+func (sigmoid) Forward(z float64) float64 {
+
+	// avoid overflow of exp for large negative values
+	if z < 0 {
+		e := math.Exp(z)
+		return e / (1 + e)
+	}
+	return 1 / (1 + math.Exp(-z))
+
+}
+
+func (sigmoid) Derivative(z float64, y float64) float64 { return y * (1 - y) }
+
+type hyperbolic struct{}
+
+func (hyperbolic) Name() string { return "tanh" }
+
+func (hyperbolic) Forward(z float64) float64 { return math.Tanh(z) }
+
+func (hyperbolic) Derivative(z float64, y float64) float64 { return 1 - y*y }
+
+type relu struct{}
+
+func (relu) Name() string { return "relu" }
+
+func (relu) Forward(z float64) float64 { return math.Max(0, z) }
+
+func (relu) Derivative(z float64, y float64) float64 {
 
-	if d >= 0.0 {
+	if z > 0 {
 		return 1.0
 	}
 	return 0.0
 
 }
 
-func HeavysideTransferDerivate(d float64) float64 {
+type leakyReLU struct{ alpha float64 }
 
-	return 1.0
+func (a leakyReLU) Name() string { return parametricName("leaky_relu", a.alpha, DefaultLeakyReLUAlpha) }
+
+func (a leakyReLU) Forward(z float64) float64 {
+
+	if z > 0 {
+		return z
+	}
+	return a.alpha * z
+
+}
+
+func (a leakyReLU) Derivative(z float64, y float64) float64 {
+
+	if z > 0 {
+		return 1.0
+	}
+	return a.alpha
 
 }
 
-func SigmoidalTransfer(d float64) float64 {
+type elu struct{ alpha float64 }
+
+func (a elu) Name() string { return parametricName("elu", a.alpha, DefaultELUAlpha) }
 
-	return 1 / (1 + math.Pow(math.E, - d))
+func (a elu) Forward(z float64) float64 {
+
+	if z > 0 {
+		return z
+	}
+	return a.alpha * math.Expm1(z)
 
 }
 
-func SigmoidalTransferDerivate(d float64) float64 {
+func (a elu) Derivative(z float64, y float64) float64 {
 
-	return 1.0
+	if z > 0 {
+		return 1.0
+	}
+	// alpha * e^z = y + alpha
+	return y + a.alpha
 
 }
 
-func HyperbolicTransfer(d float64) float64 {
+type softplus struct{}
+
+func (softplus) Name() string { return "softplus" }
+
+func (softplus) Forward(z float64) float64 {
 
-	return math.Tanh(d)
+	// log(1 + e^z) = max(z, 0) + log(1 + e^-|z|)
+	return math.Max(z, 0) + math.Log1p(math.Exp(-math.Abs(z)))
 
 }
 
-func HyperbolicTransferDerivate(d float64) float64 {
+func (softplus) Derivative(z float64, y float64) float64 { return sigmoid{}.Forward(z) }
+
+type linear struct{}
+
+func (linear) Name() string { return "linear" }
+
+func (linear) Forward(z float64) float64 { return z }
+
+func (linear) Derivative(z float64, y float64) float64 { return 1.0 }
+
+type softmax struct{}
+
+func (softmax) Name() string { return "softmax" }
+
+// Forward of a single unit softmax is always 1.
+func (softmax) Forward(z float64) float64 { return 1.0 }
+
+// Derivative returns the diagonal of the softmax jacobian.
+func (softmax) Derivative(z float64, y float64) float64 { return y * (1 - y) }
+
+func (softmax) ForwardVector(z []float64, y []float64) {
+
+	// subtract max for numerical stability
+	m := math.Inf(-1)
+	for _, v := range z {
+		m = math.Max(m, v)
+	}
+
+	s := 0.0
+	for i, v := range z {
+		y[i] = math.Exp(v - m)
+		s += y[i]
+	}
+	for i := range y {
+		y[i] /= s
+	}
+
+}
+
+func (softmax) BackwardVector(z []float64, y []float64, d []float64, g []float64) {
+
+	// J = diag(y) - y y^T, so J^T d = y * (d - <d, y>)
+	dy := 0.0
+	for i := range y {
+		dy += d[i] * y[i]
+	}
+	for i := range y {
+		g[i] = y[i] * (d[i] - dy)
+	}
+
+}
+
+// transfer functions of previous versions, kept so that existing callers still compile: derivatives take the
+// output of the unit, as they did
+
+// legacyActivation returns the registered built-in activation that replaces a transfer function of previous versions.
+func legacyActivation(name string) Activation {
+
+	a, err := ActivationByName(name)
+	if err != nil {
+		panic(err)
+	}
+	return a
+
+}
+
+// HeavysideTransfer computes the step function of d.
+//
+// Deprecated: use Heaviside, or ActivationByName("heaviside").
+func HeavysideTransfer(d float64) float64 { return legacyActivation("heaviside").Forward(d) }
+
+// HeavysideTransferDerivate computes the derivative of the step function given its output d, taken as 1.
+//
+// Deprecated: use Heaviside, or ActivationByName("heaviside").
+func HeavysideTransferDerivate(d float64) float64 { return legacyActivation("heaviside").Derivative(math.NaN(), d) }
+
+// SigmoidalTransfer computes the logistic function of d.
+//
+// Deprecated: use Sigmoid, or ActivationByName("sigmoid").
+func SigmoidalTransfer(d float64) float64 { return legacyActivation("sigmoid").Forward(d) }
+
+// SigmoidalTransferDerivate computes the derivative of the logistic function, given its output d.
+// Previous versions returned 1.
+//
+// Deprecated: use Sigmoid, or ActivationByName("sigmoid").
+func SigmoidalTransferDerivate(d float64) float64 { return legacyActivation("sigmoid").Derivative(math.NaN(), d) }
 
-	return 1 - math.Pow(d, 2)
+// HyperbolicTransfer computes the hyperbolic tangent of d.
+//
+// Deprecated: use Tanh, or ActivationByName("tanh").
+func HyperbolicTransfer(d float64) float64 { return legacyActivation("tanh").Forward(d) }
 
-}
\ No newline at end of file
+// HyperbolicTransferDerivate computes the derivative of the hyperbolic tangent, given its output d.
+//
+// Deprecated: use Tanh, or ActivationByName("tanh").
+func HyperbolicTransferDerivate(d float64) float64 { return legacyActivation("tanh").Derivative(math.NaN(), d) }
@@ -0,0 +1,130 @@
+// Neural provides struct to represents most common neural networks model and algorithms to train / test them.
+package neural
+
+import (
+
+	// sys import
+	"math"
+	"testing"
+
+)
+
+// finiteStep represents the step of central differences
+const finiteStep = 1e-6
+
+// finiteTolerance represents the largest difference between derivatives and central differences
+const finiteTolerance = 1e-6
+
+// finitePoints represents pre-activation values where derivatives are checked, away from kinks in 0
+var finitePoints = []float64{-3.1, -1.2, -0.4, 0.3, 0.9, 2.7}
+
+// #######################################################################################
+
+// TestActivationDerivatives checks Derivative of every registered activation, and of parametric ones with a
+// parameter, against central differences of Forward.
+func TestActivationDerivatives(t *testing.T) {
+
+	names := append(ActivationNames(), "leaky_relu(0.2)", "elu(0.5)")
+	for _, name := range names {
+
+		a, err := ActivationByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch a.(type) {
+		case heaviside:
+			// straight-through: the derivative is 1 by design, not the (null) one of the step
+			continue
+		case VectorActivation:
+			// checked on the whole layer by TestVectorActivationBackward
+			continue
+		}
+
+		for _, z := range finitePoints {
+			want := (a.Forward(z+finiteStep) - a.Forward(z-finiteStep)) / (2 * finiteStep)
+			if got := a.Derivative(z, a.Forward(z)); math.Abs(got-want) > finiteTolerance {
+				t.Errorf("%s: derivative in %v is %v, central difference %v", name, z, got, want)
+			}
+		}
+
+	}
+
+}
+
+// TestVectorActivationBackward checks BackwardVector and the diagonal Derivative of every registered vector
+// activation against central differences of ForwardVector.
+func TestVectorActivationBackward(t *testing.T) {
+
+	z := []float64{0.5, -1.3, 2.1, 0.05}
+	d := []float64{0.7, -0.2, 1.1, -0.9}
+	n := len(z)
+
+	for _, name := range ActivationNames() {
+
+		a, _ := ActivationByName(name)
+		v, ok := a.(VectorActivation)
+		if !ok {
+			continue
+		}
+
+		y := make([]float64, n)
+		v.ForwardVector(z, y)
+		g := make([]float64, n)
+		v.BackwardVector(z, y, d, g)
+
+		// column j of the jacobian: dy / dz_j
+		zp, zm, yp, ym := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+		for j := range z {
+
+			copy(zp, z)
+			copy(zm, z)
+			zp[j] += finiteStep
+			zm[j] -= finiteStep
+			v.ForwardVector(zp, yp)
+			v.ForwardVector(zm, ym)
+
+			// (J^T d)_j = sum_i d_i dy_i / dz_j
+			want := 0.0
+			for i := range d {
+				want += d[i] * (yp[i] - ym[i]) / (2 * finiteStep)
+			}
+			if math.Abs(g[j]-want) > finiteTolerance {
+				t.Errorf("%s: backward %d is %v, central difference %v", name, j, g[j], want)
+			}
+
+			diagonal := (yp[j] - ym[j]) / (2 * finiteStep)
+			if got := v.Derivative(z[j], y[j]); math.Abs(got-diagonal) > finiteTolerance {
+				t.Errorf("%s: derivative %d is %v, central difference %v", name, j, got, diagonal)
+			}
+
+		}
+
+	}
+
+}
+
+// TestDeprecatedTransfers checks that transfer functions of previous versions compute the registered activations.
+func TestDeprecatedTransfers(t *testing.T) {
+
+	legacy := []struct {
+		a                  Activation
+		transfer, derivate func(float64) float64
+	}{
+		{Heaviside, HeavysideTransfer, HeavysideTransferDerivate},
+		{Sigmoid, SigmoidalTransfer, SigmoidalTransferDerivate},
+		{Tanh, HyperbolicTransfer, HyperbolicTransferDerivate},
+	}
+	for _, l := range legacy {
+		for _, z := range finitePoints {
+			y := l.a.Forward(z)
+			if got := l.transfer(z); got != y {
+				t.Errorf("%s: transfer of %v is %v, want %v", l.a.Name(), z, got, y)
+			}
+			if got, want := l.derivate(y), l.a.Derivative(z, y); got != want {
+				t.Errorf("%s: derivative in %v is %v, want %v", l.a.Name(), z, got, want)
+			}
+		}
+	}
+
+}
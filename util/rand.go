@@ -0,0 +1,45 @@
+// Util provides util to handle common tasks: file and struct operations, string manipulation, etc.
+package util
+
+// Source is a splitmix64 source of pseudo random numbers for math/rand (use rand.New(source)).
+// Unlike sources of math/rand, its whole state is a single number that can be saved and restored,
+// so that a randomized run can be resumed exactly where it stopped.
+type Source struct {
+	state uint64
+}
+
+// NewSource returns a Source seeded with seed.
+func NewSource(seed int64) *Source {
+	return &Source{state: uint64(seed)}
+}
+
+// Seed sets the state of the source to seed.
+func (s *Source) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// Uint64 returns a pseudo random 64 bit value.
+func (s *Source) Uint64() uint64 {
+
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+
+}
+
+// Int63 returns a pseudo random non negative 63 bit value.
+func (s *Source) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// State returns the state of the source.
+func (s *Source) State() uint64 {
+	return s.state
+}
+
+// SetState restores a state returned by State.
+func (s *Source) SetState(state uint64) {
+	s.state = state
+}
@@ -22,11 +22,21 @@ func init() {
 	log.SetLevel(log.DebugLevel)
 }
 
-// Random return pseudo random number in [min, max]
-func Random(min, max int) int {
+// Rand returns rng, or a new generator seeded with current time if rng is nil.
+// Pass the same seeded generator everywhere to reproduce an experiment.
+func Rand(rng *rand.Rand) *rand.Rand {
+
+	if rng == nil {
+		return rand.New(NewSource(time.Now().UTC().UnixNano()))
+	}
+	return rng
+
+}
+
+// Random return pseudo random number in [min, max] drawn from rng (see Rand).
+func Random(min, max int, rng *rand.Rand) int {
 	max = max + 1
-	rand.Seed(time.Now().Unix())
-	return rand.Intn(max-min) + min
+	return Rand(rng).Intn(max-min) + min
 }
 
 // StringInSlice looks for a string in slice.
@@ -131,17 +141,17 @@ func MaxInSlice(v []float64) (float64, int) {
 	return mv, mi
 }
 
-func GenerateRandomIntWithBinaryDim(d int) int64 {
+// GenerateRandomIntWithBinaryDim return pseudo random number in [0, 2^d) drawn from rng (see Rand).
+func GenerateRandomIntWithBinaryDim(d int, rng *rand.Rand) int64 {
 
-	rand.Seed(time.Now().UTC().UnixNano())
-	return rand.Int63n(int64(2^d))
+	return Rand(rng).Int63n(int64(1) << uint(d))
 
 }
 
-func GenerateRandomBinaryInt(d int) []float64 {
+// GenerateRandomBinaryInt return binary digits of a pseudo random number in [0, 2^d) drawn from rng (see Rand).
+func GenerateRandomBinaryInt(d int, rng *rand.Rand) []float64 {
 
-	rand.Seed(time.Now().UTC().UnixNano())
-	bn := rand.Int63n(int64(2^d))
+	bn := GenerateRandomIntWithBinaryDim(d, rng)
 	bi := make([]float64, d)
 	bs := strconv.FormatInt(bn, 2)
 	zn := d-len(bs)
@@ -205,4 +215,13 @@ func Round(val float64, roundOn float64, places int ) (newVal float64) {
 	}
 	newVal = round / pow
 	return
-}
\ No newline at end of file
+}
+
+// classTolerance represents the largest relative difference between two values of the same class
+const classTolerance = 1e-9
+
+// SameClass returns true if a and b represent the same class, that is they are equal up to rounding errors
+// (classes are values of SingleExpectation and predictions of models).
+func SameClass(a float64, b float64) bool {
+	return math.Abs(a-b) <= classTolerance*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
@@ -0,0 +1,259 @@
+package validation
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+	// internal import
+	mm "github.com/made2591/go-perceptron-go/metrics"
+	mn "github.com/made2591/go-perceptron-go/model/neural"
+	mu "github.com/made2591/go-perceptron-go/util"
+)
+
+// Options represents optional settings of validation.
+type Options struct {
+
+	// Workers represents maximum number of folds trained at the same time (0 uses runtime.GOMAXPROCS(0), 1 is sequential)
+	Workers int
+	// Stratify represents if splits keep the proportions of classes of patterns in train and test patterns of each fold:
+	// classes are values of SingleExpectation, or combinations of MultipleExpectation for multi-label patterns
+	Stratify bool
+
+}
+
+// result represents the outcome of a validation: score and evaluation of test patterns of each fold.
+type result struct {
+	scores      []float64
+	evaluations []*mm.Evaluation
+}
+
+// #######################################################################################
+
+// validationOptions returns optional settings passed, or defaults if none.
+func validationOptions(opts []Options) Options {
+
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Workers < 1 {
+		opt.Workers = runtime.GOMAXPROCS(0)
+	}
+	return opt
+
+}
+
+// trainTestIndexes splits n patterns in train and test (see TrainTestPatternsSplit).
+func trainTestIndexes(n int, percentage float64, shuffle int, rng *rand.Rand) Fold {
+
+	// create splitting pivot
+	pivot := int(float64(n) * percentage)
+
+	// if mixed mode, split with shuffling
+	order := identity(n)
+	if shuffle == 1 {
+		order = mu.Rand(rng).Perm(n)
+	}
+
+	return Fold{Train: order[:pivot], Test: order[pivot:]}
+
+}
+
+// kFoldIndexes splits n patterns in k parts, the first n % k of one more pattern (see KFoldPatternsSplit).
+// It returns an error if n patterns cannot be split in k parts.
+func kFoldIndexes(n int, k int, shuffle int, rng *rand.Rand) ([][]int, error) {
+
+	if err := checkFolds(k, n); err != nil {
+		return nil, err
+	}
+
+	// if mixed mode, split with shuffling
+	order := identity(n)
+	if shuffle == 1 {
+		order = mu.Rand(rng).Perm(n)
+	}
+
+	parts := make([][]int, k)
+	start := 0
+	for f := range parts {
+		size := n / k
+		if f < n%k {
+			size++
+		}
+		parts[f] = order[start : start+size]
+		start += size
+	}
+	return parts, nil
+
+}
+
+// identity returns indexes from 0 to n-1, in order.
+func identity(n int) []int {
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+
+}
+
+// subset returns patterns of the given indexes, in order.
+func subset(patterns []mn.Pattern, indexes []int) []mn.Pattern {
+
+	s := make([]mn.Pattern, len(indexes))
+	for i, p := range indexes {
+		s[i] = patterns[p]
+	}
+	return s
+
+}
+
+// patterns returns train and test patterns of fold f.
+func (f Fold) patterns(patterns []mn.Pattern) (train []mn.Pattern, test []mn.Pattern) {
+	return subset(patterns, f.Train), subset(patterns, f.Test)
+}
+
+// subsamplingFolds draws n random train / test splits of patterns, stratified if required, all before training
+// so that they depend only on rng.
+func subsamplingFolds(patterns []mn.Pattern, percentage float64, n int, shuffle int, rng *rand.Rand, stratify bool) []Fold {
+
+	folds := make([]Fold, n)
+	for t := range folds {
+		if stratify {
+			folds[t] = stratifiedTrainTestIndexes(patterns, percentage, shuffle, rng)
+		} else {
+			folds[t] = trainTestIndexes(len(patterns), percentage, shuffle, rng)
+		}
+	}
+	return folds
+
+}
+
+// kFolds splits patterns in k folds, stratified if required: in the t-th, the t-th part is used as test
+// and the others as train. It returns an error if patterns cannot be split in k folds.
+func kFolds(patterns []mn.Pattern, k int, shuffle int, rng *rand.Rand, stratify bool) ([]Fold, error) {
+
+	var parts [][]int
+	var err error
+	if stratify {
+		parts, err = stratifiedKFoldIndexes(patterns, k, shuffle, rng)
+	} else {
+		parts, err = kFoldIndexes(len(patterns), k, shuffle, rng)
+	}
+	if err != nil {
+		return nil, err
+	}
+	folds := make([]Fold, k)
+	for t := range folds {
+		for i := range parts {
+			if i != t {
+				folds[t].Train = append(folds[t].Train, parts[i]...)
+			}
+		}
+		folds[t].Test = parts[t]
+	}
+	return folds, nil
+
+}
+
+// evaluate trains a clone of model on each fold of patterns and scores it on the test patterns of the fold, running at most
+// opt.Workers folds at the same time. Clones are made in order of fold, before training, and each score depends
+// only on its fold, so scores are the same whatever the number of workers.
+// If a fold fails (or ctx is done before it starts), it returns results of folds before it and its error.
+func evaluate(ctx context.Context, method string, model mn.Model, patterns []mn.Pattern, folds []Fold, opt Options) (result, error) {
+
+	scores := make([]float64, len(folds))
+	evaluations := make([]*mm.Evaluation, len(folds))
+	errs := make([]error, len(folds))
+	models := make([]mn.Model, len(folds))
+	for t := range folds {
+		models[t] = model.Clone()
+	}
+
+	// workers take folds in order from a channel
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opt.Workers && w < len(folds); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range next {
+				if err := ctx.Err(); err != nil {
+					errs[t] = &mn.InterruptedError{Err: err}
+					continue
+				}
+				scores[t], evaluations[t], errs[t] = score(ctx, models[t], patterns, folds[t])
+				if errs[t] != nil {
+					continue
+				}
+
+				log.WithFields(log.Fields{
+					"level":             "info",
+					"place":             "validation",
+					"method":            method,
+					"foldNumber":        t,
+					"trainSetLen":       len(folds[t].Train),
+					"testSetLen":        len(folds[t].Test),
+					"percentageCorrect": scores[t],
+				}).Info("Evaluation completed for current fold.")
+			}
+		}()
+	}
+	for t := range folds {
+		next <- t
+	}
+	close(next)
+	wg.Wait()
+
+	for t, err := range errs {
+		if err != nil {
+			return result{scores: scores[:t], evaluations: evaluations[:t]}, err
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"level":     "info",
+		"place":     "validation",
+		"method":    method,
+		"folds":     len(folds),
+		"workers":   opt.Workers,
+		"meanScore": mean(scores),
+	}).Info("Evaluation completed for all folds.")
+
+	return result{scores: scores, evaluations: evaluations}, nil
+
+}
+
+// score trains model on train patterns of fold f and predicts the class of its test patterns.
+// It returns the percentage of test patterns whose class is predicted correctly and the evaluation of predictions.
+func score(ctx context.Context, model mn.Model, patterns []mn.Pattern, f Fold) (float64, *mm.Evaluation, error) {
+
+	// train model with set of patterns
+	train, test := f.patterns(patterns)
+	if _, err := model.Fit(ctx, train); err != nil {
+		return 0, nil, err
+	}
+
+	// compute predictions for each pattern in testing set
+	e := mm.Evaluate(model, test)
+	_, percentageCorrect := mn.Accuracy(e.Actual, e.Predicted)
+	return percentageCorrect, e, nil
+
+}
+
+// mean returns the average of scores.
+func mean(scores []float64) float64 {
+
+	acc := 0.0
+	for i := 0; i < len(scores); i++ {
+		acc += scores[i]
+	}
+	return acc / float64(len(scores))
+
+}
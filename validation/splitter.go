@@ -0,0 +1,341 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+	// internal import
+	mm "github.com/made2591/go-perceptron-go/metrics"
+	mn "github.com/made2591/go-perceptron-go/model/neural"
+	mu "github.com/made2591/go-perceptron-go/util"
+)
+
+// Splitter represents a resampling strategy, that splits patterns in folds of train and test patterns.
+// Splitters are returned by KFold, StratifiedKFold, RepeatedKFold, RepeatedStratifiedKFold, LeaveOneOut,
+// Bootstrap, GroupKFold and TimeSeriesSplit, and used by CrossValidation.
+type Splitter interface {
+
+	// Split returns folds of patterns, drawing random numbers from rng (nil uses a generator seeded with current time).
+	// It returns an error if patterns cannot be split as required
+	Split(patterns []mn.Pattern, rng *rand.Rand) ([]Fold, error)
+
+}
+
+// Fold represents indexes of patterns used to train and to test the model in a fold.
+type Fold struct {
+
+	// Train represents indexes of training patterns, in order of training (an index may appear more than once)
+	Train []int
+	// Test represents indexes of test patterns
+	Test []int
+
+}
+
+// kFold splits patterns in k parts, testing each one in turn.
+type kFold struct {
+	k        int
+	shuffle  int
+	stratify bool
+	repeats  int
+}
+
+// leaveOneOut tests each pattern alone.
+type leaveOneOut struct{}
+
+// bootstrap trains on patterns drawn with replacement and tests on the others.
+type bootstrap struct {
+	rounds int
+}
+
+// groupKFold splits groups of patterns in k parts.
+type groupKFold struct {
+	k      int
+	groups []int
+}
+
+// timeSeriesSplit tests consecutive blocks of patterns, training on the patterns before them.
+type timeSeriesSplit struct {
+	k        int
+	maxTrain int
+}
+
+// #######################################################################################
+
+// KFold returns a splitter in k folds, as KFoldValidation: the t-th fold tests the t-th part of patterns.
+// if shuffle is 0 parts keep the order of patterns, otherwise patterns are shuffled before partitioning
+func KFold(k int, shuffle int) Splitter {
+	return &kFold{k: k, shuffle: shuffle, repeats: 1}
+}
+
+// StratifiedKFold returns a splitter in k folds as KFold, keeping in each part the proportions of classes
+// (see Options.Stratify).
+func StratifiedKFold(k int, shuffle int) Splitter {
+	return &kFold{k: k, shuffle: shuffle, stratify: true, repeats: 1}
+}
+
+// RepeatedKFold returns a splitter in k folds as KFold, repeated with a different shuffling of patterns
+// each time: it returns k * repeats folds.
+func RepeatedKFold(k int, repeats int) Splitter {
+	return &kFold{k: k, shuffle: 1, repeats: repeats}
+}
+
+// RepeatedStratifiedKFold returns a splitter in k stratified folds as StratifiedKFold, repeated with a different
+// shuffling of patterns of each class each time: it returns k * repeats folds.
+func RepeatedStratifiedKFold(k int, repeats int) Splitter {
+	return &kFold{k: k, shuffle: 1, stratify: true, repeats: repeats}
+}
+
+// LeaveOneOut returns a splitter with a fold for each pattern, that tests it training on all the others.
+// It suits very small datasets, as it trains a model per pattern.
+func LeaveOneOut() Splitter {
+	return leaveOneOut{}
+}
+
+// Bootstrap returns a splitter in rounds folds: each one trains on as many patterns as given, drawn with
+// replacement, and tests on the patterns never drawn (out-of-bag), about 37% of them.
+func Bootstrap(rounds int) Splitter {
+	return &bootstrap{rounds: rounds}
+}
+
+// GroupKFold returns a splitter in k folds that never splits a group across folds: all patterns of a group are
+// tested in the same fold, so that the model is always tested on groups it has not seen in training.
+// [groups:[]int] is the group id of each pattern; groups are dealt largest first to the smallest fold, for folds
+// of similar size
+func GroupKFold(k int, groups []int) Splitter {
+	return &groupKFold{k: k, groups: groups}
+}
+
+// TimeSeriesSplit returns a splitter in k folds for ordered patterns (e.g. sequences for Elman networks), with
+// forward chaining: patterns are divided in k + 1 consecutive blocks and the t-th fold tests the (t+1)-th block,
+// training on the patterns before it, in order. Patterns are never shuffled and never tested before training.
+// [maxTrain:int] is the maximum number of training patterns, the latest ones (0 is all the patterns before the test)
+func TimeSeriesSplit(k int, maxTrain int) Splitter {
+	return &timeSeriesSplit{k: k, maxTrain: maxTrain}
+}
+
+// checkFolds returns an error if n patterns cannot be split in k folds.
+func checkFolds(k int, n int) error {
+
+	if k < 2 || k > n {
+		return fmt.Errorf("validation: cannot split %d patterns in %d folds", n, k)
+	}
+	return nil
+
+}
+
+func (s *kFold) Split(patterns []mn.Pattern, rng *rand.Rand) ([]Fold, error) {
+
+	if s.repeats < 1 {
+		return nil, fmt.Errorf("validation: %d repeats of k-fold", s.repeats)
+	}
+
+	// the same generator for all repeats
+	if s.shuffle == 1 {
+		rng = mu.Rand(rng)
+	}
+	var folds []Fold
+	for r := 0; r < s.repeats; r++ {
+		fs, err := kFolds(patterns, s.k, s.shuffle, rng, s.stratify)
+		if err != nil {
+			return nil, err
+		}
+		folds = append(folds, fs...)
+	}
+	return folds, nil
+
+}
+
+func (leaveOneOut) Split(patterns []mn.Pattern, rng *rand.Rand) ([]Fold, error) {
+
+	n := len(patterns)
+	if n < 2 {
+		return nil, fmt.Errorf("validation: cannot leave one out of %d patterns", n)
+	}
+
+	folds := make([]Fold, n)
+	for i := range folds {
+		folds[i].Train = make([]int, 0, n-1)
+		for j := 0; j < n; j++ {
+			if j != i {
+				folds[i].Train = append(folds[i].Train, j)
+			}
+		}
+		folds[i].Test = []int{i}
+	}
+	return folds, nil
+
+}
+
+// Split draws each round until some pattern is out of bag.
+func (s *bootstrap) Split(patterns []mn.Pattern, rng *rand.Rand) ([]Fold, error) {
+
+	n := len(patterns)
+	if n < 2 || s.rounds < 1 {
+		return nil, fmt.Errorf("validation: cannot bootstrap %d rounds of %d patterns", s.rounds, n)
+	}
+
+	rng = mu.Rand(rng)
+	folds := make([]Fold, s.rounds)
+	for r := range folds {
+		for len(folds[r].Test) == 0 {
+			drawn := make([]bool, n)
+			folds[r].Train = make([]int, n)
+			for i := range folds[r].Train {
+				p := rng.Intn(n)
+				folds[r].Train[i], drawn[p] = p, true
+			}
+			for p, d := range drawn {
+				if !d {
+					folds[r].Test = append(folds[r].Test, p)
+				}
+			}
+		}
+	}
+	return folds, nil
+
+}
+
+func (s *groupKFold) Split(patterns []mn.Pattern, rng *rand.Rand) ([]Fold, error) {
+
+	if len(s.groups) != len(patterns) {
+		return nil, fmt.Errorf("validation: %d group ids for %d patterns", len(s.groups), len(patterns))
+	}
+
+	// patterns of each group, groups in order of appearance
+	var members [][]int
+	index := map[int]int{}
+	for i, g := range s.groups {
+		m, ok := index[g]
+		if !ok {
+			m = len(members)
+			index[g] = m
+			members = append(members, nil)
+		}
+		members[m] = append(members[m], i)
+	}
+	if err := checkFolds(s.k, len(members)); err != nil {
+		return nil, fmt.Errorf("validation: cannot split %d groups in %d folds", len(members), s.k)
+	}
+
+	// largest groups first, each one to the smallest fold
+	sort.SliceStable(members, func(a, b int) bool { return len(members[a]) > len(members[b]) })
+	parts := make([][]int, s.k)
+	for _, m := range members {
+		smallest := 0
+		for f := range parts {
+			if len(parts[f]) < len(parts[smallest]) {
+				smallest = f
+			}
+		}
+		parts[smallest] = append(parts[smallest], m...)
+	}
+
+	folds := make([]Fold, s.k)
+	for t := range folds {
+		for f := range parts {
+			if f != t {
+				folds[t].Train = append(folds[t].Train, parts[f]...)
+			}
+		}
+		folds[t].Test = parts[t]
+		sort.Ints(folds[t].Train)
+		sort.Ints(folds[t].Test)
+	}
+	return folds, nil
+
+}
+
+func (s *timeSeriesSplit) Split(patterns []mn.Pattern, rng *rand.Rand) ([]Fold, error) {
+
+	n := len(patterns)
+	if s.k < 1 || n < s.k+1 {
+		return nil, fmt.Errorf("validation: cannot split %d ordered patterns in %d folds", n, s.k)
+	}
+
+	// the first block, for training only, gets the remainder
+	size := n / (s.k + 1)
+	folds := make([]Fold, s.k)
+	for t := range folds {
+		start := n - (s.k-t)*size
+		from := 0
+		if s.maxTrain > 0 && start > s.maxTrain {
+			from = start - s.maxTrain
+		}
+		folds[t] = Fold{Train: identity(start)[from:], Test: identity(start + size)[start:]}
+	}
+	return folds, nil
+
+}
+
+// CrossValidation perform evaluation on a model (see neural.Model) with the folds made by splitter s, drawn
+// with rng (nil uses current time). Each fold trains a clone of model on its train patterns and tests it on
+// its test patterns: model is left unchanged. Folds run concurrently as specified by optional validation
+// settings (see Options); Stratify is ignored, as splits are made by s.
+// It returns scores reached for each fold, nil if s cannot split patterns.
+func CrossValidation(model mn.Model, patterns []mn.Pattern, s Splitter, rng *rand.Rand, opts ...Options) []float64 {
+
+	scores, err := CrossValidationContext(context.Background(), model, patterns, s, rng, opts...)
+	if err != nil && scores == nil {
+		log.WithFields(log.Fields{
+			"level":  "error",
+			"place":  "validation",
+			"method": "CrossValidation",
+			"error":  err,
+		}).Error("Failed to split patterns.")
+	}
+	return scores
+
+}
+
+// CrossValidationContext is like CrossValidation but stops training when ctx is done:
+// in that case it returns scores of folds completed and the error of the trainer (see neural.InterruptedError).
+func CrossValidationContext(ctx context.Context, model mn.Model, patterns []mn.Pattern, s Splitter, rng *rand.Rand, opts ...Options) ([]float64, error) {
+
+	fs, err := s.Split(patterns, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := evaluate(ctx, "CrossValidation", model, patterns, fs, validationOptions(opts))
+	return r.scores, err
+
+}
+
+// CrossValidationEvaluation perform evaluation on a model as CrossValidation, pooling the predictions on test
+// patterns of all folds, to compute measures beyond accuracy (see package metrics): for example, its Report.
+// It returns the evaluation, nil if s cannot split patterns.
+func CrossValidationEvaluation(model mn.Model, patterns []mn.Pattern, s Splitter, rng *rand.Rand, opts ...Options) *mm.Evaluation {
+
+	e, err := CrossValidationEvaluationContext(context.Background(), model, patterns, s, rng, opts...)
+	if err != nil && e == nil {
+		log.WithFields(log.Fields{
+			"level":  "error",
+			"place":  "validation",
+			"method": "CrossValidationEvaluation",
+			"error":  err,
+		}).Error("Failed to split patterns.")
+	}
+	return e
+
+}
+
+// CrossValidationEvaluationContext is like CrossValidationEvaluation but stops training when ctx is done:
+// in that case it returns predictions of folds completed and the error of the trainer (see neural.InterruptedError).
+func CrossValidationEvaluationContext(ctx context.Context, model mn.Model, patterns []mn.Pattern, s Splitter, rng *rand.Rand, opts ...Options) (*mm.Evaluation, error) {
+
+	fs, err := s.Split(patterns, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := evaluate(ctx, "CrossValidationEvaluation", model, patterns, fs, validationOptions(opts))
+	e := &mm.Evaluation{}
+	e.Merge(r.evaluations...)
+	return e, err
+
+}
@@ -0,0 +1,197 @@
+package validation
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	// internal import
+	mn "github.com/made2591/go-perceptron-go/model/neural"
+)
+
+// TestKFoldSplitters checks sizes of folds of k-fold splitters, and that each repetition covers patterns without
+// overlap between train and test.
+func TestKFoldSplitters(t *testing.T) {
+
+	patterns := countPatterns(6, 4)
+	splitters := []struct {
+		name    string
+		s       Splitter
+		repeats int
+		sizes   []int
+	}{
+		// the first 10 % 4 parts have one more pattern
+		{"k-fold", KFold(4, 0), 1, []int{3, 3, 2, 2}},
+		{"shuffled k-fold", KFold(4, 1), 1, []int{3, 3, 2, 2}},
+		{"stratified k-fold", StratifiedKFold(4, 1), 1, []int{3, 3, 2, 2}},
+		{"repeated k-fold", RepeatedKFold(5, 2), 2, []int{2, 2, 2, 2, 2, 2, 2, 2, 2, 2}},
+		{"repeated stratified k-fold", RepeatedStratifiedKFold(2, 3), 3, []int{5, 5, 5, 5, 5, 5}},
+		{"leave one out", LeaveOneOut(), 1, []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}},
+	}
+
+	for _, sp := range splitters {
+
+		folds, err := sp.s.Split(patterns, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatalf("%s: %v", sp.name, err)
+		}
+		sizes := make([]int, len(folds))
+		for f := range folds {
+			sizes[f] = len(folds[f].Test)
+		}
+		if !reflect.DeepEqual(sizes, sp.sizes) {
+			t.Errorf("%s: folds test %v patterns, want %v", sp.name, sizes, sp.sizes)
+		}
+
+		k := len(folds) / sp.repeats
+		for r := 0; r < sp.repeats; r++ {
+			if err := checkPartition(folds[r*k:(r+1)*k], len(patterns)); err != nil {
+				t.Errorf("%s: repetition %d: %v", sp.name, r, err)
+			}
+		}
+
+	}
+
+	// repetitions are shuffled differently
+	folds, _ := RepeatedKFold(2, 2).Split(patterns, rand.New(rand.NewSource(1)))
+	if reflect.DeepEqual(folds[0], folds[2]) {
+		t.Error("repetitions of k-fold are the same")
+	}
+
+}
+
+// TestGroupKFold checks that GroupKFold never splits a group across train and test, with folds of similar size.
+func TestGroupKFold(t *testing.T) {
+
+	groups := []int{0, 0, 0, 1, 1, 2, 2, 2, 2, 3, 4, 4}
+	patterns := countPatterns(len(groups))
+	folds, err := GroupKFold(3, groups).Split(patterns, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPartition(folds, len(patterns)); err != nil {
+		t.Error(err)
+	}
+
+	for f, fold := range folds {
+		// groups of 4, 3, 2, 2 and 1 patterns dealt to the smallest fold
+		if len(fold.Test) != 4 {
+			t.Errorf("fold %d tests %d patterns, want 4", f, len(fold.Test))
+		}
+		tested := map[int]bool{}
+		for _, i := range fold.Test {
+			tested[groups[i]] = true
+		}
+		for _, i := range fold.Train {
+			if tested[groups[i]] {
+				t.Errorf("fold %d trains and tests group %d", f, groups[i])
+			}
+		}
+	}
+
+}
+
+// TestTimeSeriesSplit checks that training patterns of each fold are consecutive and precede test patterns.
+func TestTimeSeriesSplit(t *testing.T) {
+
+	patterns := countPatterns(11)
+	for _, maxTrain := range []int{0, 3} {
+
+		folds, err := TimeSeriesSplit(3, maxTrain).Split(patterns, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// blocks of 2 patterns, the first one of 5 for training only
+		for f, fold := range folds {
+			start := 5 + 2*f
+			if want := []int{start, start + 1}; !reflect.DeepEqual(fold.Test, want) {
+				t.Errorf("max train %d: fold %d tests %v, want %v", maxTrain, f, fold.Test, want)
+			}
+			from := 0
+			if maxTrain > 0 {
+				from = start - maxTrain
+			}
+			if len(fold.Train) != start-from {
+				t.Errorf("max train %d: fold %d trains on %d patterns, want %d", maxTrain, f, len(fold.Train), start-from)
+			}
+			for j, i := range fold.Train {
+				if i != from+j || i >= fold.Test[0] {
+					t.Fatalf("max train %d: fold %d trains on %v, not the patterns just before its test", maxTrain, f, fold.Train)
+				}
+			}
+		}
+
+	}
+
+}
+
+// TestBootstrap checks that out-of-bag patterns of each round are the patterns never drawn for training.
+func TestBootstrap(t *testing.T) {
+
+	patterns := countPatterns(20)
+	folds, err := Bootstrap(5).Split(patterns, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(folds) != 5 {
+		t.Fatalf("bootstrap of 5 rounds has %d folds", len(folds))
+	}
+
+	for r, fold := range folds {
+		if len(fold.Train) != len(patterns) || len(fold.Test) == 0 {
+			t.Errorf("round %d trains on %d patterns and tests %d", r, len(fold.Train), len(fold.Test))
+		}
+		drawn := map[int]bool{}
+		for _, i := range fold.Train {
+			drawn[i] = true
+		}
+		var oob []int
+		for i := range patterns {
+			if !drawn[i] {
+				oob = append(oob, i)
+			}
+		}
+		test := append([]int(nil), fold.Test...)
+		sort.Ints(test)
+		if !reflect.DeepEqual(test, oob) {
+			t.Errorf("round %d tests %v, out-of-bag patterns are %v", r, fold.Test, oob)
+		}
+	}
+
+}
+
+// TestSplitterErrors checks that splitters return an error when patterns cannot be split as requested.
+func TestSplitterErrors(t *testing.T) {
+
+	patterns := countPatterns(5, 5)
+	splitters := map[string]struct {
+		s        Splitter
+		patterns []mn.Pattern
+	}{
+		"1 fold":                          {KFold(1, 0), patterns},
+		"more folds than patterns":        {KFold(11, 1), patterns},
+		"stratified, 1 fold":              {StratifiedKFold(1, 0), patterns},
+		"no repeats":                      {RepeatedKFold(2, 0), patterns},
+		"leave one out of 1 pattern":      {LeaveOneOut(), patterns[:1]},
+		"no bootstrap rounds":             {Bootstrap(0), patterns},
+		"bootstrap of 1 pattern":          {Bootstrap(3), patterns[:1]},
+		"group ids of other patterns":     {GroupKFold(2, []int{0, 1, 2}), patterns},
+		"more folds than groups":          {GroupKFold(3, []int{0, 0, 0, 0, 0, 1, 1, 1, 1, 1}), patterns},
+		"no time series folds":            {TimeSeriesSplit(0, 0), patterns},
+		"time series of too few patterns": {TimeSeriesSplit(10, 0), patterns},
+	}
+
+	for name, sp := range splitters {
+		if folds, err := sp.s.Split(sp.patterns, rand.New(rand.NewSource(1))); err == nil {
+			t.Errorf("%s: split in %d folds without error", name, len(folds))
+		}
+	}
+
+	// validation functions return no scores
+	if scores := KFoldValidation(mlpModel(1, mn.TrainOptions{}), patterns, 11, 0, nil); scores != nil {
+		t.Errorf("k-fold validation in more folds than patterns has scores %v", scores)
+	}
+
+}
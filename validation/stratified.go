@@ -0,0 +1,140 @@
+package validation
+
+import (
+	"math/rand"
+	"sort"
+
+	// third part import
+	log "github.com/sirupsen/logrus"
+
+	// internal import
+	mn "github.com/made2591/go-perceptron-go/model/neural"
+	mu "github.com/made2591/go-perceptron-go/util"
+)
+
+// StratifiedTrainTestPatternsSplit split an array of patterns in training and testing, as TrainTestPatternsSplit,
+// keeping in both the proportions of classes (see Options.Stratify).
+// if shuffle is 0 the function takes the first percentage items of each class as train and the other as test,
+// keeping their order, otherwise patterns of each class are shuffled before partitioning, using rng (nil uses current time)
+func StratifiedTrainTestPatternsSplit(patterns []mn.Pattern, percentage float64, shuffle int, rng *rand.Rand) (train []mn.Pattern, test []mn.Pattern) {
+
+	f := stratifiedTrainTestIndexes(patterns, percentage, shuffle, rng)
+	train, test = f.patterns(patterns)
+
+	log.WithFields(log.Fields{
+		"level":     "info",
+		"msg":       "stratified splitting completed",
+		"trainSet":  len(train),
+		"testSet: ": len(test),
+	}).Info("Complete splitting train/test set.")
+
+	return train, test
+}
+
+// StratifiedKFoldPatternsSplit split an array of patterns in k subsets, as KFoldPatternsSplit,
+// keeping in each one the proportions of classes (see Options.Stratify).
+// if shuffle is 0 the function partitions the items of each class maintaining the order
+// otherwise patterns of each class are shuffled before partitioning, using rng (nil uses current time)
+func StratifiedKFoldPatternsSplit(patterns []mn.Pattern, k int, shuffle int, rng *rand.Rand) [][]mn.Pattern {
+
+	parts, err := stratifiedKFoldIndexes(patterns, k, shuffle, rng)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"level":  "error",
+			"place":  "validation",
+			"method": "StratifiedKFoldPatternsSplit",
+			"error":  err,
+		}).Error("Failed to split patterns.")
+		return nil
+	}
+	folds := make([][]mn.Pattern, k)
+	for f := range parts {
+		folds[f] = subset(patterns, parts[f])
+	}
+
+	log.WithFields(log.Fields{
+		"level":              "info",
+		"msg":                "stratified splitting completed",
+		"numberOfFolds":      k,
+		"meanFoldSize: ":     len(patterns) / k,
+		"consideredElements": len(patterns),
+	}).Info("Complete folds splitting.")
+
+	return folds
+}
+
+// arrange puts indexes in their original order, or in random order if shuffle is 1.
+func arrange(indexes []int, shuffle int, rng *rand.Rand) {
+
+	if shuffle == 1 {
+		rng.Shuffle(len(indexes), func(i, j int) { indexes[i], indexes[j] = indexes[j], indexes[i] })
+		return
+	}
+	sort.Ints(indexes)
+
+}
+
+// stratifiedTrainTestIndexes splits patterns in train and test (see StratifiedTrainTestPatternsSplit):
+// the first percentage of each class, rounded, is used as train.
+func stratifiedTrainTestIndexes(patterns []mn.Pattern, percentage float64, shuffle int, rng *rand.Rand) Fold {
+
+	rng = mu.Rand(rng)
+
+	var f Fold
+	for _, g := range mn.ClassGroups(patterns) {
+		arrange(g, shuffle, rng)
+		pivot := int(float64(len(g))*percentage + 0.5)
+		f.Train, f.Test = append(f.Train, g[:pivot]...), append(f.Test, g[pivot:]...)
+	}
+
+	// classes are not left in blocks
+	arrange(f.Train, shuffle, rng)
+	arrange(f.Test, shuffle, rng)
+	return f
+
+}
+
+// stratifiedKFoldIndexes splits patterns in k parts (see StratifiedKFoldPatternsSplit): patterns of each class
+// are dealt to parts in turn, continuing from the part after the last one of the previous class, so that
+// parts differ at most by one pattern in size and by one pattern of each class.
+// It returns an error if patterns cannot be split in k parts.
+func stratifiedKFoldIndexes(patterns []mn.Pattern, k int, shuffle int, rng *rand.Rand) ([][]int, error) {
+
+	if err := checkFolds(k, len(patterns)); err != nil {
+		return nil, err
+	}
+	rng = mu.Rand(rng)
+
+	parts := make([][]int, k)
+	next, small := 0, 0
+	for _, g := range mn.ClassGroups(patterns) {
+
+		if len(g) < k {
+			small++
+		}
+
+		arrange(g, shuffle, rng)
+		for _, i := range g {
+			parts[next] = append(parts[next], i)
+			next = (next + 1) % k
+		}
+
+	}
+
+	if small > 0 {
+		log.WithFields(log.Fields{
+			"level":   "warning",
+			"place":   "validation",
+			"method":  "stratifiedKFold",
+			"classes": small,
+			"folds":   k,
+		}).Warn("Classes with fewer patterns than folds: some folds will not test them.")
+	}
+
+	// classes are not left in blocks
+	for f := range parts {
+		arrange(parts[f], shuffle, rng)
+	}
+	return parts, nil
+
+}
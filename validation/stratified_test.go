@@ -0,0 +1,149 @@
+package validation
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	// internal import
+	mn "github.com/made2591/go-perceptron-go/model/neural"
+)
+
+// countPatterns returns patterns with counts[c] patterns of class c, sorted by class.
+func countPatterns(counts ...int) []mn.Pattern {
+
+	var patterns []mn.Pattern
+	for c, n := range counts {
+		for i := 0; i < n; i++ {
+			patterns = append(patterns, mn.Pattern{Features: []float64{float64(len(patterns))}, SingleExpectation: float64(c)})
+		}
+	}
+	return patterns
+
+}
+
+// classCounts returns the number of patterns of each class among indexes.
+func classCounts(patterns []mn.Pattern, indexes []int) map[float64]int {
+
+	counts := map[float64]int{}
+	for _, i := range indexes {
+		counts[patterns[i].SingleExpectation]++
+	}
+	return counts
+
+}
+
+// checkFold checks that train and test patterns of fold are disjoint and cover the n patterns.
+func checkFold(fold Fold, n int) error {
+
+	seen := make([]int, n)
+	for _, i := range append(append([]int(nil), fold.Train...), fold.Test...) {
+		seen[i]++
+	}
+	for i, s := range seen {
+		if s != 1 {
+			return fmt.Errorf("pattern %d is %d times in train and test", i, s)
+		}
+	}
+	return nil
+
+}
+
+// checkPartition checks each fold with checkFold, and that test patterns of folds cover each pattern once.
+func checkPartition(folds []Fold, n int) error {
+
+	tested := make([]int, n)
+	for f, fold := range folds {
+		if err := checkFold(fold, n); err != nil {
+			return fmt.Errorf("fold %d: %v", f, err)
+		}
+		for _, i := range fold.Test {
+			tested[i]++
+		}
+	}
+	for i, s := range tested {
+		if s != 1 {
+			return fmt.Errorf("pattern %d is tested %d times", i, s)
+		}
+	}
+	return nil
+
+}
+
+// TestStratifiedKFold checks that stratified folds keep the proportions of classes, cover patterns without overlap
+// between train and test, and spread classes with fewer patterns than folds.
+func TestStratifiedKFold(t *testing.T) {
+
+	for _, shuffle := range []int{0, 1} {
+
+		// 5 folds of 6, 3 and 1 patterns of each class
+		patterns := countPatterns(30, 15, 5)
+		folds, err := kFolds(patterns, 5, shuffle, rand.New(rand.NewSource(1)), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkPartition(folds, len(patterns)); err != nil {
+			t.Errorf("shuffle %d: %v", shuffle, err)
+		}
+		for f, fold := range folds {
+			if c := classCounts(patterns, fold.Test); c[0] != 6 || c[1] != 3 || c[2] != 1 {
+				t.Errorf("shuffle %d: fold %d tests %v patterns of each class, want 6, 3 and 1", shuffle, f, c)
+			}
+		}
+
+		// 3 patterns of class 1 in 5 folds: at most one in each, sizes differ at most by one
+		patterns = countPatterns(12, 3)
+		folds, err = kFolds(patterns, 5, shuffle, rand.New(rand.NewSource(1)), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkPartition(folds, len(patterns)); err != nil {
+			t.Errorf("shuffle %d, small class: %v", shuffle, err)
+		}
+		for f, fold := range folds {
+			if c := classCounts(patterns, fold.Test); c[1] > 1 || len(fold.Test) != 3 {
+				t.Errorf("shuffle %d, small class: fold %d tests %v patterns of each class", shuffle, f, c)
+			}
+		}
+
+	}
+
+	// classes are the combinations of MultipleExpectation
+	patterns := make([]mn.Pattern, 12)
+	for i := range patterns {
+		patterns[i].MultipleExpectation = []float64{float64(i % 2), float64(i / 6)}
+	}
+	parts, err := stratifiedKFoldIndexes(patterns, 3, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for f, part := range parts {
+		seen := map[string]int{}
+		for _, i := range part {
+			seen[fmt.Sprint(patterns[i].MultipleExpectation)]++
+		}
+		if len(seen) != 4 {
+			t.Errorf("part %d holds combinations %v, want 4 of 4", f, seen)
+		}
+	}
+
+}
+
+// TestStratifiedTrainTestSplit checks that train and test keep the proportions of classes and cover patterns
+// without overlap.
+func TestStratifiedTrainTestSplit(t *testing.T) {
+
+	patterns := countPatterns(30, 15, 5)
+	for _, shuffle := range []int{0, 1} {
+
+		f := stratifiedTrainTestIndexes(patterns, 0.8, shuffle, rand.New(rand.NewSource(1)))
+		if err := checkFold(f, len(patterns)); err != nil {
+			t.Errorf("shuffle %d: %v", shuffle, err)
+		}
+		if c := classCounts(patterns, f.Train); c[0] != 24 || c[1] != 12 || c[2] != 4 {
+			t.Errorf("shuffle %d: train holds %v patterns of each class, want 24, 12 and 4", shuffle, c)
+		}
+
+	}
+
+}
@@ -1,8 +1,8 @@
 package validation
 
 import (
+	"context"
 	"math/rand"
-	"time"
 
 	// third part import
 	log "github.com/sirupsen/logrus"
@@ -16,34 +16,11 @@ import (
 
 // TrainTestPatternsSplit split an array of patterns in training and testing.
 // if shuffle is 0 the function takes the first percentage items as train and the other as test
-// otherwise the patterns array is shuffled before partitioning
-func TrainTestPatternsSplit(patterns []mn.Pattern, percentage float64, shuffle int) (train []mn.Pattern, test []mn.Pattern) {
-
-	// create splitting pivot
-	var splitPivot int = int(float64(len(patterns)) * percentage)
-	train = make([]mn.Pattern, splitPivot)
-	test = make([]mn.Pattern, len(patterns)-splitPivot)
-
-	// if mixed mode, split with shuffling
-	if shuffle == 1 {
-		// create random indexes permutation
-		rand.Seed(time.Now().UTC().UnixNano())
-		perm := rand.Perm(len(patterns))
-
-		// copy training data
-		for i := 0; i < splitPivot; i++ {
-			train[i] = patterns[perm[i]]
-		}
-		// copy test data
-		for i := 0; i < len(patterns)-splitPivot; i++ {
-			test[i] = patterns[perm[i]]
-		}
+// otherwise the patterns array is shuffled before partitioning, using rng (nil uses current time)
+func TrainTestPatternsSplit(patterns []mn.Pattern, percentage float64, shuffle int, rng *rand.Rand) (train []mn.Pattern, test []mn.Pattern) {
 
-	} else {
-		// else, split without shuffle
-		train = patterns[:splitPivot]
-		test = patterns[splitPivot:]
-	}
+	f := trainTestIndexes(len(patterns), percentage, shuffle, rng)
+	train, test = f.patterns(patterns)
 
 	log.WithFields(log.Fields{
 		"level":     "info",
@@ -55,423 +32,269 @@ func TrainTestPatternsSplit(patterns []mn.Pattern, percentage float64, shuffle i
 	return train, test
 }
 
-// TrainTestPatternsSplit split an array of patterns in training and testing.
-// if shuffle is 0 the function takes the first percentage items as train and the other as test
-// otherwise the patterns array is shuffled before partitioning
-func TrainTestPatternSplit(patterns []mn.Pattern, percentage float64, shuffle int) (train []mn.Pattern, test []mn.Pattern) {
-
-	// create splitting pivot
-	var splitPivot int = int(float64(len(patterns)) * percentage)
-	train = make([]mn.Pattern, splitPivot)
-	test = make([]mn.Pattern, len(patterns)-splitPivot)
-
-	// if mixed mode, split with shuffling
-	if shuffle == 1 {
-		// create random indexes permutation
-		rand.Seed(time.Now().UTC().UnixNano())
-		perm := rand.Perm(len(patterns))
-
-		// copy training data
-		for i := 0; i < splitPivot; i++ {
-			train[i] = patterns[perm[i]]
-		}
-		// copy test data
-		for i := 0; i < len(patterns)-splitPivot; i++ {
-			test[i] = patterns[perm[i]]
-		}
-
-	} else {
-		// else, split without shuffle
-		train = patterns[:splitPivot]
-		test = patterns[splitPivot:]
-	}
-
-	log.WithFields(log.Fields{
-		"level":     "info",
-		"msg":       "splitting completed",
-		"trainSet":  len(train),
-		"testSet: ": len(test),
-	}).Info("Complete splitting train/test set.")
-
-	return train, test
+// TrainTestPatternSplit split an array of patterns in training and testing, as TrainTestPatternsSplit.
+func TrainTestPatternSplit(patterns []mn.Pattern, percentage float64, shuffle int, rng *rand.Rand) (train []mn.Pattern, test []mn.Pattern) {
+	return TrainTestPatternsSplit(patterns, percentage, shuffle, rng)
 }
 
 // KFoldPatternsSplit split an array of patterns in k subsets.
 // if shuffle is 0 the function partitions the items maintaining the order
-// otherwise the patterns array is shuffled before partitioning
-func KFoldPatternsSplit(patterns []mn.Pattern, k int, shuffle int) [][]mn.Pattern {
-
-	// get the size of each fold
-	var size = int(len(patterns) / k)
-	var freeElements = int(len(patterns) % k)
+// otherwise the patterns array is shuffled before partitioning, using rng (nil uses current time)
+// It returns nil if patterns cannot be split in k subsets (k < 2 or more than patterns).
+func KFoldPatternsSplit(patterns []mn.Pattern, k int, shuffle int, rng *rand.Rand) [][]mn.Pattern {
 
-	folds := make([][]mn.Pattern, k)
-
-	var perm []int
-	// if mixed mode, split with shuffling
-	if shuffle == 1 {
-		// create random indexes permutation
-		rand.Seed(time.Now().UTC().UnixNano())
-		perm = rand.Perm(len(patterns))
+	parts, err := kFoldIndexes(len(patterns), k, shuffle, rng)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"level":  "error",
+			"place":  "validation",
+			"method": "KFoldPatternsSplit",
+			"error":  err,
+		}).Error("Failed to split patterns.")
+		return nil
 	}
-
-	// start splitting
-	currSize := 0
-	foldStart := 0
-	curr := 0
-	for f := 0; f < k; f++ {
-		curr = foldStart
-		currSize = size
-		if f < freeElements {
-			// add another
-			currSize++
-		}
-
-		// create array
-		folds[f] = make([]mn.Pattern, currSize)
-
-		// copy elements
-
-		for i := 0; i < currSize; i++ {
-			if shuffle == 1 {
-				folds[f][i] = patterns[perm[curr]]
-			} else {
-				folds[f][i] = patterns[curr]
-			}
-			curr++
-		}
-
-		foldStart = curr
-
+	folds := make([][]mn.Pattern, k)
+	for f := range parts {
+		folds[f] = subset(patterns, parts[f])
 	}
 
 	log.WithFields(log.Fields{
 		"level":              "info",
 		"msg":                "splitting completed",
 		"numberOfFolds":      k,
-		"meanFoldSize: ":     size,
-		"consideredElements": (size * k) + freeElements,
+		"meanFoldSize: ":     len(patterns) / k,
+		"consideredElements": len(patterns),
 	}).Info("Complete folds splitting.")
 
 	return folds
 }
 
-// RandomSubsamplingValidation perform evaluation on neuron algorithm.
+// RandomSubsamplingValidation perform evaluation on a model (see neural.Model).
+// Each fold trains a clone of model on a random split of patterns: model is left unchanged.
+// Folds run concurrently and splits are stratified as specified by optional validation settings (see Options).
 // It returns scores reached for each fold iteration.
-func RandomSubsamplingValidation(neuron *mn.NeuronUnit, patterns []mn.Pattern, percentage float64, epochs int, folds int, shuffle int) []float64 {
+func RandomSubsamplingValidation(model mn.Model, patterns []mn.Pattern, percentage float64, folds int, shuffle int, rng *rand.Rand, opts ...Options) []float64 {
 
-	// results and predictions vars init
-	var scores, actual, predicted []float64
-	var train, test []mn.Pattern
+	scores, _ := RandomSubsamplingValidationContext(context.Background(), model, patterns, percentage, folds, shuffle, rng, opts...)
+	return scores
 
-	scores = make([]float64, folds)
+}
 
-	for t := 0; t < folds; t++ {
-		// split the dataset with shuffling
-		train, test = TrainTestPatternsSplit(patterns, percentage, shuffle)
+// RandomSubsamplingValidationContext is like RandomSubsamplingValidation but stops training when ctx is done:
+// in that case it returns scores of folds completed and the error of the trainer (see neural.InterruptedError).
+func RandomSubsamplingValidationContext(ctx context.Context, model mn.Model, patterns []mn.Pattern, percentage float64, folds int, shuffle int, rng *rand.Rand, opts ...Options) ([]float64, error) {
 
-		// train neuron with set of patterns, for specified number of epochs
-		mn.TrainNeuron(neuron, train, epochs, 1)
+	// split the dataset with shuffling, for each fold
+	opt := validationOptions(opts)
+	fs := subsamplingFolds(patterns, percentage, folds, shuffle, rng, opt.Stratify)
 
-		// compute predictions for each pattern in testing set
-		for _, pattern := range test {
-			actual = append(actual, pattern.SingleExpectation)
-			predicted = append(predicted, mn.Predict(neuron, &pattern))
-		}
+	r, err := evaluate(ctx, "RandomSubsamplingValidation", model, patterns, fs, opt)
+	return r.scores, err
 
-		// compute score
-		_, percentageCorrect := mn.Accuracy(actual, predicted)
-		scores[t] = percentageCorrect
+}
 
-		log.WithFields(log.Fields{
-			"level":             "info",
-			"place":             "validation",
-			"method":            "RandomSubsamplingValidation",
-			"foldNumber":        t,
-			"trainSetLen":       len(train),
-			"testSetLen":        len(test),
-			"percentageCorrect": percentageCorrect,
-		}).Info("Evaluation completed for current fold.")
-	}
+// KFoldValidation perform evaluation on a model (see neural.Model).
+// Each fold trains a clone of model, using the t-th part of patterns as test: model is left unchanged.
+// Folds run concurrently and parts are stratified as specified by optional validation settings (see Options).
+// It returns scores reached for each fold iteration, nil if patterns cannot be split in k folds.
+func KFoldValidation(model mn.Model, patterns []mn.Pattern, k int, shuffle int, rng *rand.Rand, opts ...Options) []float64 {
 
-	// compute average score
-	acc := 0.0
-	for i := 0; i < len(scores); i++ {
-		acc += scores[i]
+	scores, err := KFoldValidationContext(context.Background(), model, patterns, k, shuffle, rng, opts...)
+	if err != nil && scores == nil {
+		log.WithFields(log.Fields{
+			"level":  "error",
+			"place":  "validation",
+			"method": "KFoldValidation",
+			"error":  err,
+		}).Error("Failed to split patterns.")
 	}
-
-	mean := acc / float64(len(scores))
-
-	log.WithFields(log.Fields{
-		"level":       "info",
-		"place":       "validation",
-		"method":      "RandomSubsamplingValidation",
-		"folds":       folds,
-		"trainSetLen": len(train),
-		"testSetLen":  len(test),
-		"meanScore":   mean,
-	}).Info("Evaluation completed for all folds.")
-
 	return scores
-}
-
-// RandomSubsamplingValidation perform evaluation on neuron algorithm.
-// It returns scores reached for each fold iteration.
-func KFoldValidation(neuron *mn.NeuronUnit, patterns []mn.Pattern, epochs int, k int, shuffle int) []float64 {
 
-	// results and predictions vars init
-	var scores, actual, predicted []float64
-	var train, test []mn.Pattern
+}
 
-	scores = make([]float64, k)
+// KFoldValidationContext is like KFoldValidation but stops training when ctx is done:
+// in that case it returns scores of folds completed and the error of the trainer (see neural.InterruptedError).
+// It returns no scores and an error if patterns cannot be split in k folds.
+func KFoldValidationContext(ctx context.Context, model mn.Model, patterns []mn.Pattern, k int, shuffle int, rng *rand.Rand, opts ...Options) ([]float64, error) {
 
 	// split the dataset with shuffling
-	folds := KFoldPatternsSplit(patterns, k, shuffle)
-
-	// the t-th fold is used as test
-	for t := 0; t < k; t++ {
-		// prepare train
-		train = nil
-		for i := 0; i < k; i++ {
-			if i != t {
-				train = append(train, folds[i]...)
-			}
-		}
-		test = folds[t]
+	opt := validationOptions(opts)
+	fs, err := kFolds(patterns, k, shuffle, rng, opt.Stratify)
+	if err != nil {
+		return nil, err
+	}
 
-		// train neuron with set of patterns, for specified number of epochs
-		mn.TrainNeuron(neuron, train, epochs, 1)
+	r, err := evaluate(ctx, "KFoldValidation", model, patterns, fs, opt)
+	return r.scores, err
 
-		// compute predictions for each pattern in testing set
-		for _, pattern := range test {
-			actual = append(actual, pattern.SingleExpectation)
-			predicted = append(predicted, mn.Predict(neuron, &pattern))
-		}
+}
 
-		// compute score
-		_, percentageCorrect := mn.Accuracy(actual, predicted)
-		scores[t] = percentageCorrect
+// KFoldPredictions predicts the class of each pattern of dataset d with a clone of model trained on the other
+// folds, as in KFoldValidation, and decodes it to its class name (see neural.Dataset.Decode).
+// It returns the predictions, in order of patterns, nil if patterns cannot be split in k folds.
+func KFoldPredictions(model mn.Model, d *mn.Dataset, k int, shuffle int, rng *rand.Rand, opts ...Options) []string {
 
+	predictions, err := KFoldPredictionsContext(context.Background(), model, d, k, shuffle, rng, opts...)
+	if err != nil {
 		log.WithFields(log.Fields{
-			"level":             "info",
-			"place":             "validation",
-			"method":            "KFoldValidation",
-			"foldNumber":        t,
-			"trainSetLen":       len(train),
-			"testSetLen":        len(test),
-			"percentageCorrect": percentageCorrect,
-		}).Info("Evaluation completed for current fold.")
-	}
-
-	// compute average score
-	acc := 0.0
-	for i := 0; i < len(scores); i++ {
-		acc += scores[i]
+			"level":  "error",
+			"place":  "validation",
+			"method": "KFoldPredictions",
+			"error":  err,
+		}).Error("Failed to predict patterns.")
 	}
-
-	mean := acc / float64(len(scores))
-
-	log.WithFields(log.Fields{
-		"level":       "info",
-		"place":       "validation",
-		"method":      "KFoldValidation",
-		"folds":       k,
-		"trainSetLen": len(train),
-		"testSetLen":  len(test),
-		"meanScore":   mean,
-	}).Info("Evaluation completed for all folds.")
-
-	return scores
+	return predictions
 
 }
 
-// It returns scores reached for each fold iteration.
-func MLPRandomSubsamplingValidation(mlp *mn.MultiLayerNetwork, patterns []mn.Pattern, percentage float64, epochs int, folds int, shuffle int, mapped []string) []float64 {
-
-	// results and predictions vars init
-	var scores, actual, predicted []float64
-	var train, test []mn.Pattern
-
-	scores = make([]float64, folds)
-
-	for t := 0; t < folds; t++ {
-		// split the dataset with shuffling
-		train, test = TrainTestPatternsSplit(patterns, percentage, shuffle)
-
-		// train mlp with set of patterns, for specified number of epochs
-		mn.MLPTrain(mlp, patterns, mapped, epochs)
-
-		// compute predictions for each pattern in testing set
-		for _, pattern := range test {
-			// get actual
-			actual = append(actual, pattern.SingleExpectation)
-			// get output from network
-			o_out := mn.Execute(mlp, &pattern)
-			// get index of max output
-			_, indexMaxOut := mu.MaxInSlice(o_out)
-			// add to predicted values
-			predicted = append(predicted, float64(indexMaxOut))
-		}
+// KFoldPredictionsContext is like KFoldPredictions but stops training when ctx is done:
+// in that case it returns no predictions and the error of the trainer (see neural.InterruptedError).
+// It returns an error if patterns cannot be split in k folds.
+func KFoldPredictionsContext(ctx context.Context, model mn.Model, d *mn.Dataset, k int, shuffle int, rng *rand.Rand, opts ...Options) ([]string, error) {
 
-		// compute score
-		_, percentageCorrect := mn.Accuracy(actual, predicted)
-		scores[t] = percentageCorrect
+	// split the dataset with shuffling
+	opt := validationOptions(opts)
+	fs, err := kFolds(d.Patterns, k, shuffle, rng, opt.Stratify)
+	if err != nil {
+		return nil, err
+	}
 
-		log.WithFields(log.Fields{
-			"level":             "info",
-			"place":             "validation",
-			"method":            "MLPRandomSubsamplingValidation",
-			"foldNumber":        t,
-			"trainSetLen":       len(train),
-			"testSetLen":        len(test),
-			"percentageCorrect": percentageCorrect,
-		}).Info("Evaluation completed for current fold.")
+	r, err := evaluate(ctx, "KFoldPredictions", model, d.Patterns, fs, opt)
+	if err != nil {
+		return nil, err
 	}
 
-	// compute average score
-	acc := 0.0
-	for i := 0; i < len(scores); i++ {
-		acc += scores[i]
+	// each pattern is tested in exactly one fold
+	predictions := make([]string, d.Len())
+	for t, f := range fs {
+		for i, p := range f.Test {
+			predictions[p] = d.Decode(r.evaluations[t].Predicted[i])
+		}
 	}
+	return predictions, nil
 
-	mean := acc / float64(len(scores))
+}
 
-	log.WithFields(log.Fields{
-		"level":       "info",
-		"place":       "validation",
-		"method":      "MLPRandomSubsamplingValidation",
-		"folds":       folds,
-		"trainSetLen": len(train),
-		"testSetLen":  len(test),
-		"meanScore":   mean,
-	}).Info("Evaluation completed for all folds.")
+// ResubstitutionValidation trains model on all patterns and tests it on the same ones.
+// It returns the mean score and the score of each pattern (100 if its class is predicted correctly, 0 otherwise).
+func ResubstitutionValidation(model mn.Model, patterns []mn.Pattern) (float64, []float64) {
+
+	mean, scores, _ := ResubstitutionValidationContext(context.Background(), model, patterns)
+	return mean, scores
 
-	return scores
 }
 
-// RandomSubsamplingValidation perform evaluation on neuron algorithm.
-// It returns scores reached for each fold iteration.
-func MLPKFoldValidation(mlp *mn.MultiLayerNetwork, patterns []mn.Pattern, epochs int, k int, shuffle int, mapped []string) []float64 {
+// ResubstitutionValidationContext is like ResubstitutionValidation but stops training when ctx is done:
+// in that case it returns no scores and the error of the trainer (see neural.InterruptedError).
+func ResubstitutionValidationContext(ctx context.Context, model mn.Model, patterns []mn.Pattern) (float64, []float64, error) {
+	return resubstitution(ctx, "ResubstitutionValidation", model, patterns, classScore)
+}
 
-	// results and predictions vars init
-	var scores, actual, predicted []float64
-	var train, test []mn.Pattern
+// DigitResubstitutionValidation trains model on all patterns and tests it on the same ones, digit by digit:
+// the score of a pattern is the percentage of outputs of PredictProba, rounded, equal to its MultipleExpectation
+// (as the binary digits of the sum computed by ElmanModel).
+// It returns the mean score and the score of each pattern.
+func DigitResubstitutionValidation(model mn.Model, patterns []mn.Pattern) (float64, []float64) {
 
-	scores = make([]float64, k)
+	mean, scores, _ := DigitResubstitutionValidationContext(context.Background(), model, patterns)
+	return mean, scores
 
-	// split the dataset with shuffling
-	folds := KFoldPatternsSplit(patterns, k, shuffle)
-
-	// the t-th fold is used as test
-	for t := 0; t < k; t++ {
-		// prepare train
-		train = nil
-		for i := 0; i < k; i++ {
-			if i != t {
-				train = append(train, folds[i]...)
-			}
-		}
-		test = folds[t]
-
-		// train mlp with set of patterns, for specified number of epochs
-		mn.MLPTrain(mlp, patterns, mapped, epochs)
-
-		// compute predictions for each pattern in testing set
-		for _, pattern := range test {
-			// get actual
-			actual = append(actual, pattern.SingleExpectation)
-			// get output from network
-			o_out := mn.Execute(mlp, &pattern)
-			// get index of max output
-			_, indexMaxOut := mu.MaxInSlice(o_out)
-			// add to predicted values
-			predicted = append(predicted, float64(indexMaxOut))
-		}
+}
+
+// DigitResubstitutionValidationContext is like DigitResubstitutionValidation but stops training when ctx is done:
+// in that case it returns no scores and the error of the trainer (see neural.InterruptedError).
+func DigitResubstitutionValidationContext(ctx context.Context, model mn.Model, patterns []mn.Pattern) (float64, []float64, error) {
+	return resubstitution(ctx, "DigitResubstitutionValidation", model, patterns, digitScore)
+}
 
-		// compute score
-		_, percentageCorrect := mn.Accuracy(actual, predicted)
-		scores[t] = percentageCorrect
+// resubstitution trains model on all patterns and scores each of them with score.
+// It returns the mean score and the score of each pattern.
+func resubstitution(ctx context.Context, method string, model mn.Model, patterns []mn.Pattern, score func(mn.Model, *mn.Pattern) float64) (float64, []float64, error) {
 
-		log.WithFields(log.Fields{
-			"level":             "info",
-			"place":             "validation",
-			"method":            "MLPKFoldValidation",
-			"foldNumber":        t,
-			"trainSetLen":       len(train),
-			"testSetLen":        len(test),
-			"percentageCorrect": percentageCorrect,
-		}).Info("Evaluation completed for current fold.")
+	// train model with set of patterns
+	if _, err := model.Fit(ctx, patterns); err != nil {
+		return 0, nil, err
 	}
 
-	// compute average score
-	acc := 0.0
-	for i := 0; i < len(scores); i++ {
-		acc += scores[i]
+	// compute predictions for each pattern in training set
+	scores := make([]float64, len(patterns))
+	for i := range patterns {
+		scores[i] = score(model, &patterns[i])
 	}
 
-	mean := acc / float64(len(scores))
+	m := mean(scores)
 
 	log.WithFields(log.Fields{
 		"level":       "info",
 		"place":       "validation",
-		"method":      "MLPKFoldValidation",
-		"folds":       k,
-		"trainSetLen": len(train),
-		"testSetLen":  len(test),
-		"meanScore":   mean,
-	}).Info("Evaluation completed for all folds.")
+		"method":      method,
+		"trainSetLen": len(patterns),
+		"testSetLen":  len(patterns),
+		"meanScore":   m,
+	}).Info("Evaluation completed for all patterns.")
 
-	return scores
+	return m, scores, nil
 
 }
 
-// RNNValidation perform evaluation on neuron algorithm.
-func RNNValidation(mlp *mn.MultiLayerNetwork, patterns []mn.Pattern, epochs int, shuffle int) (float64, []float64) {
+// classScore returns 100 if the class of pattern p is predicted by model, 0 otherwise.
+func classScore(model mn.Model, p *mn.Pattern) float64 {
 
-	// results and predictions vars init
-	var scores []float64
-	scores = make([]float64, len(patterns))
+	_, s := mn.Accuracy([]float64{p.SingleExpectation}, []float64{model.Predict(p.Features)})
+	return s
 
-	// train mlp with set of patterns, for specified number of epochs
-	mn.ElmanTrain(mlp, patterns, epochs)
-	p_cor := 0.0
+}
 
-	// compute predictions for each pattern in testing set
-	for p_i, pattern := range patterns {
-		// get output from network
-		o_out := mn.Execute(mlp, &pattern, 1)
-		for o_out_i, o_out_v := range(o_out) {
-			o_out[o_out_i] = mu.Round(o_out_v, .5, 0)
-		}
-		log.WithFields(log.Fields{
-			"a_p_b":	pattern.Features,
-			"rea_c":	pattern.MultipleExpectation,
-			"pre_c":	o_out,
-		}).Debug()
-
-		// add to predicted values
-		_, p_cor = mn.Accuracy(pattern.MultipleExpectation, o_out)
-		// compute score
-		scores[p_i] = p_cor;
-	}
+// digitScore returns the percentage of outputs of model for pattern p, rounded, equal to its MultipleExpectation.
+func digitScore(model mn.Model, p *mn.Pattern) float64 {
 
-	// compute average score
-	acc := 0.0
-	for i := 0; i < len(scores); i++ {
-		acc += scores[i]
+	o_out := model.PredictProba(p.Features)
+	for o_out_i, o_out_v := range o_out {
+		o_out[o_out_i] = mu.Round(o_out_v, .5, 0)
 	}
+	log.WithFields(log.Fields{
+		"a_p_b": p.Features,
+		"rea_c": p.MultipleExpectation,
+		"pre_c": o_out,
+	}).Debug()
 
-	mean := acc / float64(len(scores))
+	_, s := mn.Accuracy(p.MultipleExpectation, o_out)
+	return s
 
-	log.WithFields(log.Fields{
-		"level":       "info",
-		"place":       "validation",
-		"method":      "RNNValidation",
-		"trainSetLen": len(patterns),
-		"testSetLen":  len(patterns),
-		"meanScore":   mean,
-	}).Info("Evaluation completed for all patterns.")
+}
 
-	return mean, scores
+// MLPRandomSubsamplingValidation perform evaluation on multi layer perceptron, as RandomSubsamplingValidation
+// of MLPModel(mlp, mapped, epochs).
+// It returns scores reached for each fold iteration.
+func MLPRandomSubsamplingValidation(mlp *mn.MultiLayerNetwork, patterns []mn.Pattern, percentage float64, epochs int, folds int, shuffle int, mapped []string, rng *rand.Rand, opts ...Options) []float64 {
+	return RandomSubsamplingValidation(mn.MLPModel(mlp, mapped, epochs), patterns, percentage, folds, shuffle, rng, opts...)
+}
+
+// MLPRandomSubsamplingValidationContext is like MLPRandomSubsamplingValidation but stops training when ctx is done.
+func MLPRandomSubsamplingValidationContext(ctx context.Context, mlp *mn.MultiLayerNetwork, patterns []mn.Pattern, percentage float64, epochs int, folds int, shuffle int, mapped []string, rng *rand.Rand, opts ...Options) ([]float64, error) {
+	return RandomSubsamplingValidationContext(ctx, mn.MLPModel(mlp, mapped, epochs), patterns, percentage, folds, shuffle, rng, opts...)
+}
+
+// MLPKFoldValidation perform evaluation on multi layer perceptron, as KFoldValidation of MLPModel(mlp, mapped, epochs).
+// It returns scores reached for each fold iteration.
+func MLPKFoldValidation(mlp *mn.MultiLayerNetwork, patterns []mn.Pattern, epochs int, k int, shuffle int, mapped []string, rng *rand.Rand, opts ...Options) []float64 {
+	return KFoldValidation(mn.MLPModel(mlp, mapped, epochs), patterns, k, shuffle, rng, opts...)
+}
+
+// MLPKFoldValidationContext is like MLPKFoldValidation but stops training when ctx is done.
+func MLPKFoldValidationContext(ctx context.Context, mlp *mn.MultiLayerNetwork, patterns []mn.Pattern, epochs int, k int, shuffle int, mapped []string, rng *rand.Rand, opts ...Options) ([]float64, error) {
+	return KFoldValidationContext(ctx, mn.MLPModel(mlp, mapped, epochs), patterns, k, shuffle, rng, opts...)
+}
 
-}
\ No newline at end of file
+// RNNValidation perform evaluation on an Elman network, as DigitResubstitutionValidation of ElmanModel(mlp, epochs):
+// mlp is trained, in order, on patterns (shuffle is ignored), then the score of each pattern is the percentage
+// of its binary digits predicted (ResubstitutionValidation of ElmanModel scores the whole sum instead).
+// It returns the mean score and the score of each pattern.
+func RNNValidation(mlp *mn.MultiLayerNetwork, patterns []mn.Pattern, epochs int, shuffle int) (float64, []float64) {
+	return DigitResubstitutionValidation(mn.ElmanModel(mlp, epochs), patterns)
+}
+
+// RNNValidationContext is like RNNValidation but stops training when ctx is done.
+func RNNValidationContext(ctx context.Context, mlp *mn.MultiLayerNetwork, patterns []mn.Pattern, epochs int, shuffle int) (float64, []float64, error) {
+	return DigitResubstitutionValidationContext(ctx, mn.ElmanModel(mlp, epochs), patterns)
+}
@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	// internal import
+	mn "github.com/made2591/go-perceptron-go/model/neural"
+)
+
+// classPatterns returns n patterns of the given number of classes, sorted by class, with the index of each
+// pattern as first feature.
+func classPatterns(n int, classes int) []mn.Pattern {
+
+	patterns := make([]mn.Pattern, n)
+	for i := range patterns {
+		c := float64(i * classes / n)
+		patterns[i] = mn.Pattern{Features: []float64{float64(i), c + 0.1*float64(i%7)}, SingleExpectation: c}
+	}
+	return patterns
+
+}
+
+// mlpModel returns a small network, with training settings opt, as a model of patterns of classPatterns.
+func mlpModel(epochs int, opt mn.TrainOptions) mn.Model {
+
+	mlp := mn.PrepareMLPNet([]int{2, 4, 2}, 0.3, mn.Sigmoid, rand.New(rand.NewSource(3)))
+	return mn.MLPModel(&mlp, []string{"0", "1"}, epochs, opt)
+
+}
+
+// TestKFoldValidationCancelled checks that cancelling the context stops KFoldValidationContext during a fold,
+// returning scores of folds completed and the error of the trainer.
+func TestKFoldValidationCancelled(t *testing.T) {
+
+	patterns := classPatterns(40, 2)
+
+	// cancel in the second epoch of the second fold: clones share the callback
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	folds := 0
+	stop := mn.CallbackFuncs{
+		TrainBegin: func(s *mn.TrainState) { folds++ },
+		BatchEnd: func(s *mn.TrainState) {
+			if folds == 2 && s.Epoch == 1 {
+				cancel()
+			}
+		},
+	}
+	scores, err := KFoldValidationContext(ctx, mlpModel(5, mn.TrainOptions{Callbacks: []mn.Callback{stop}}), patterns, 4, 1, rand.New(rand.NewSource(1)), Options{Workers: 1})
+
+	var ie *mn.InterruptedError
+	if !errors.Is(err, mn.ErrInterrupted) || !errors.Is(err, context.Canceled) || !errors.As(err, &ie) {
+		t.Fatalf("error is %v, want an InterruptedError of a cancelled context", err)
+	}
+	if ie.Epoch != 1 || len(scores) != 1 || folds != 2 {
+		t.Errorf("interrupted at epoch %d of fold %d with %d scores, want epoch 1 of fold 2 and 1 score", ie.Epoch, folds, len(scores))
+	}
+
+	// folds do not start when the context is already done
+	scores, err = KFoldValidationContext(ctx, mlpModel(5, mn.TrainOptions{Callbacks: []mn.Callback{}}), patterns, 4, 1, rand.New(rand.NewSource(1)), Options{Workers: 4})
+	if !errors.Is(err, mn.ErrInterrupted) || !errors.Is(err, context.Canceled) || len(scores) != 0 {
+		t.Errorf("validation with a cancelled context returns %v, %v", scores, err)
+	}
+
+}
+
+// TestWorkersSameScores checks that folds of KFoldValidation and RandomSubsamplingValidation have the same scores
+// whatever the number of workers, given the same seed.
+func TestWorkersSameScores(t *testing.T) {
+
+	patterns := classPatterns(60, 2)
+	validations := map[string]func(model mn.Model, rng *rand.Rand, opt Options) []float64{
+		"KFoldValidation": func(model mn.Model, rng *rand.Rand, opt Options) []float64 {
+			return KFoldValidation(model, patterns, 5, 1, rng, opt)
+		},
+		"RandomSubsamplingValidation": func(model mn.Model, rng *rand.Rand, opt Options) []float64 {
+			return RandomSubsamplingValidation(model, patterns, 0.7, 5, 1, rng, opt)
+		},
+	}
+
+	for name, validate := range validations {
+
+		var scores [][]float64
+		for _, workers := range []int{1, 4} {
+			// each clone shuffles patterns with a seed drawn in order from the generator of the experiment
+			rng := rand.New(rand.NewSource(7))
+			model := mlpModel(10, mn.TrainOptions{Shuffle: true, Rand: rng, Callbacks: []mn.Callback{}})
+			scores = append(scores, validate(model, rng, Options{Workers: workers}))
+		}
+		if len(scores[0]) != 5 || !reflect.DeepEqual(scores[0], scores[1]) {
+			t.Errorf("%s: scores are %v with 1 worker, %v with 4", name, scores[0], scores[1])
+		}
+
+	}
+
+}